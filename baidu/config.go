@@ -0,0 +1,107 @@
+package baidu
+
+import (
+	"errors"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// 环境变量名
+const (
+	EnvApiKey    = "BAIDU_OCR_API_KEY"
+	EnvApiSecret = "BAIDU_OCR_API_SECRET"
+	EnvLazy      = "BAIDU_OCR_LAZY"
+)
+
+// 百度OCR配置，可直接嵌入 go-zero 服务配置，通过 conf.MustLoad 加载。
+// go-zero 的 json/yaml/toml 配置都按 json 标签匹配，标签未写名称时键名即字段名：
+//
+//	BaiduOcr:
+//	  ApiKey: xxx
+//	  ApiSecret: xxx
+//	  Lazy: true
+type Config struct {
+	ApiKey    string `json:",env=BAIDU_OCR_API_KEY"`
+	ApiSecret string `json:",env=BAIDU_OCR_API_SECRET"`
+	// 延迟初始化：创建时不请求 token，首次识别时再获取
+	Lazy bool `json:",optional"`
+}
+
+// 从环境变量读取配置
+func LoadConfigFromEnv() (Config, error) {
+	c := Config{
+		ApiKey:    os.Getenv(EnvApiKey),
+		ApiSecret: os.Getenv(EnvApiSecret),
+	}
+	if v := os.Getenv(EnvLazy); v != "" {
+		lazy, err := strconv.ParseBool(v)
+		if err != nil {
+			return c, errors.New(EnvLazy + " 取值无效！")
+		}
+		c.Lazy = lazy
+	}
+	return c, c.Validate()
+}
+
+// 校验必填项
+func (c Config) Validate() error {
+	if c.ApiKey == "" || c.ApiSecret == "" {
+		return errors.New("百度OCR缺少 ApiKey 或 ApiSecret！")
+	}
+	return nil
+}
+
+// 根据配置创建实例，cache 为空时使用进程内缓存
+func NewBaiduOcrWithConfig(c Config, cache Cache) (*BaiduOcr, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	if cache == nil {
+		cache = newMemoryCache()
+	}
+	b := &BaiduOcr{apiKey: c.ApiKey, apiSecret: c.ApiSecret, cache: cache}
+	if !c.Lazy {
+		if _, err := b.getAccessToken(); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+type memoryItem struct {
+	value    string
+	expireAt time.Time
+}
+
+// 进程内 token 缓存
+type memoryCache struct {
+	mu    sync.Mutex
+	items map[string]memoryItem
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{items: map[string]memoryItem{}}
+}
+
+func (m *memoryCache) Set(key string, value string, expires int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item := memoryItem{value: value}
+	if expires > 0 {
+		item.expireAt = time.Now().Add(time.Duration(expires) * time.Second)
+	}
+	m.items[key] = item
+	return nil
+}
+
+func (m *memoryCache) Get(key string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	item, ok := m.items[key]
+	if !ok || (!item.expireAt.IsZero() && time.Now().After(item.expireAt)) {
+		return "", nil
+	}
+	return item.value, nil
+}
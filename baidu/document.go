@@ -0,0 +1,96 @@
+package baidu
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bangongyi/toolkits/document"
+)
+
+// 图片转通用文档结构，每行文字一个段落并带位置，公式识别结果为公式块
+func (b *BaiduOcr) ImageToDocument(filePath string) (*document.Document, error) {
+	res, suffix, size, err := b.ImageToResult(filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc := res.Document()
+	doc.Source = imageSource(filepath.Base(filePath), suffix, size)
+	doc.Source.Path = filePath
+	return doc, nil
+}
+
+// 图片地址转通用文档结构
+func (b *BaiduOcr) ImageUrlToDocument(imageUrl string) (*document.Document, error) {
+	res, suffix, size, err := b.ImageUrlToResult(imageUrl)
+	if err != nil {
+		return nil, err
+	}
+	doc := res.Document()
+	doc.Source = imageSource(path.Base(imageUrl), suffix, size)
+	doc.Source.URL = imageUrl
+	return doc, nil
+}
+
+// pdf转通用文档结构，每页一页，Origin 标明文字来自文本层还是OCR
+func (b *BaiduOcr) PdfToDocument(filePath string) (*document.Document, error) {
+	pages, suffix, size, err := b.PdfToPages(filePath)
+	if err != nil {
+		return nil, err
+	}
+	doc := PagesDocument(pages)
+	doc.Source = document.Source{Name: filepath.Base(filePath), Path: filePath, Suffix: suffix, Format: "pdf", Size: size}
+	return doc, nil
+}
+
+// pdf地址转通用文档结构
+func (b *BaiduOcr) PdfUrlToDocument(pdfUrl string) (*document.Document, error) {
+	pages, suffix, size, err := b.PdfUrlToPages(pdfUrl)
+	if err != nil {
+		return nil, err
+	}
+	doc := PagesDocument(pages)
+	doc.Source = document.Source{Name: path.Base(pdfUrl), URL: pdfUrl, Suffix: suffix, Format: "pdf", Size: size}
+	return doc, nil
+}
+
+// 识别结果转通用文档结构，来源信息由调用方填写
+func (r *BodyResultResponse) Document() *document.Document {
+	page := document.Page{Kind: document.PageKindPage, Number: 1, Origin: document.OriginOcr, Blocks: []document.Block{}}
+	for _, w := range r.WordsResult {
+		page.Blocks = append(page.Blocks, document.Block{Type: document.BlockParagraph, Text: w.Words, Box: locationBox(w.Location)})
+	}
+	for _, f := range r.FormulaResult {
+		page.Blocks = append(page.Blocks, document.Block{Type: document.BlockFormula, Text: f.Words, Box: locationBox(f.Location)})
+	}
+	return &document.Document{Pages: []document.Page{page}}
+}
+
+// pdf按页识别结果转通用文档结构
+func PagesDocument(pages []PdfPage) *document.Document {
+	doc := &document.Document{Pages: make([]document.Page, 0, len(pages))}
+	for _, p := range pages {
+		origin := document.OriginText
+		if p.Source == PageSourceOcr {
+			origin = document.OriginOcr
+		}
+		doc.Pages = append(doc.Pages, document.Page{
+			Kind:   document.PageKindPage,
+			Number: p.PageNum,
+			Origin: origin,
+			Blocks: document.TextBlocks(p.Text),
+		})
+	}
+	return doc
+}
+
+func imageSource(name string, suffix string, size int) document.Source {
+	return document.Source{Name: name, Suffix: suffix, Format: strings.ToLower(suffix), Size: size}
+}
+
+func locationBox(l *Location) *document.Box {
+	if l == nil {
+		return nil
+	}
+	return &document.Box{Left: l.Left, Top: l.Top, Width: l.Width, Height: l.Height}
+}
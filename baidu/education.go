@@ -0,0 +1,21 @@
+package baidu
+
+// 手写文字识别，适用于作业、试卷中的手写答案，返回带位置信息的识别结果
+func (b *BaiduOcr) HandwritingToResult(filePath string) (result *BodyResultResponse, fileSuffix string, FileSize int, err error) {
+	return b.recognizeImage(handwritingUrlBaidu, filePath, "&recognize_granularity=big&probability=false&detect_direction=false", 4)
+}
+
+// 手写文字图片地址识别
+func (b *BaiduOcr) HandwritingUrlToResult(imageUrl string) (result *BodyResultResponse, fileSuffix string, FileSize int, err error) {
+	return b.recognizeImageUrl(handwritingUrlBaidu, imageUrl, "&recognize_granularity=big&probability=false&detect_direction=false")
+}
+
+// 公式识别，FormulaResult 中返回 LaTeX 公式，WordsResult 中返回全部文字
+func (b *BaiduOcr) FormulaToResult(filePath string) (result *BodyResultResponse, fileSuffix string, FileSize int, err error) {
+	return b.recognizeImage(formulaUrlBaidu, filePath, "&recognize_granularity=big&detect_direction=false", 4)
+}
+
+// 公式图片地址识别
+func (b *BaiduOcr) FormulaUrlToResult(imageUrl string) (result *BodyResultResponse, fileSuffix string, FileSize int, err error) {
+	return b.recognizeImageUrl(formulaUrlBaidu, imageUrl, "&recognize_granularity=big&detect_direction=false")
+}
@@ -7,23 +7,59 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
-	"os"
 	"strings"
+	"sync"
+
+	"github.com/bangongyi/toolkits/workspace"
 )
 
 var (
-	tokenUrlBaiDu     = "https://aip.baidubce.com/oauth/2.0/token"
-	transformUrlBaidu = "https://aip.baidubce.com/rest/2.0/ocr/v1/general_basic?access_token=%s"
+	tokenUrlBaiDu       = "https://aip.baidubce.com/oauth/2.0/token"
+	transformUrlBaidu   = "https://aip.baidubce.com/rest/2.0/ocr/v1/general_basic?access_token=%s"
+	handwritingUrlBaidu = "https://aip.baidubce.com/rest/2.0/ocr/v1/handwriting?access_token=%s"
+	formulaUrlBaidu     = "https://aip.baidubce.com/rest/2.0/ocr/v1/formula?access_token=%s"
 )
 
 type BodyResultResponse struct {
-	LogId          int         `json:"log_id"`
-	WordsResultNum int         `json:"words_result_num"`
-	WordsResult    []WordsList `json:"words_result"`
+	LogId            int         `json:"log_id"`
+	WordsResultNum   int         `json:"words_result_num"`
+	WordsResult      []WordsList `json:"words_result"`
+	FormulaResultNum int         `json:"formula_result_num,omitempty"`
+	FormulaResult    []WordsList `json:"formula_result,omitempty"` // 公式识别结果，Words 为 LaTeX
+	PdfFileSize      json.Number `json:"pdf_file_size,omitempty"`  // pdf总页数
+	ErrorCode        int         `json:"error_code,omitempty"`
+	ErrorMsg         string      `json:"error_msg,omitempty"`
 }
 
 type WordsList struct {
-	Words string `json:"words"`
+	Words    string    `json:"words"`
+	Location *Location `json:"location,omitempty"`
+}
+
+// 文字在图片中的位置
+type Location struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// 按行拼接识别出的文字
+func (r *BodyResultResponse) Text() string {
+	lines := make([]string, 0, len(r.WordsResult))
+	for _, val := range r.WordsResult {
+		lines = append(lines, val.Words)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 识别出的公式（LaTeX）
+func (r *BodyResultResponse) Latex() []string {
+	list := make([]string, 0, len(r.FormulaResult))
+	for _, val := range r.FormulaResult {
+		list = append(list, val.Words)
+	}
+	return list
 }
 
 type BaiDuTokenResponse struct {
@@ -46,15 +82,12 @@ type BaiduOcr struct {
 	cache     Cache
 	apiKey    string
 	apiSecret string
+	// 只在刷新 token 时加锁，并发刷新时只请求一次
+	tokenMu sync.Mutex
 }
 
 func NewBaiduOcr(apiKey string, apiSecret string, cache Cache) (*BaiduOcr, error) {
-	c := &BaiduOcr{apiKey: apiKey, apiSecret: apiSecret, cache: cache}
-	_, err := c.getAccessToken()
-	if err != nil {
-		return nil, err
-	}
-	return c, nil
+	return NewBaiduOcrWithConfig(Config{ApiKey: apiKey, ApiSecret: apiSecret}, cache)
 }
 
 // 图片转文字
@@ -82,6 +115,16 @@ func (b *BaiduOcr) ImageToWord(filePath string) (word string, fileSuffix string,
 	return str, suffix, size, nil
 }
 
+// 图片转文字，返回带位置信息的识别结果
+func (b *BaiduOcr) ImageToResult(filePath string) (result *BodyResultResponse, fileSuffix string, FileSize int, err error) {
+	return b.recognizeImage(transformUrlBaidu, filePath, "&detect_direction=false&detect_language=false&paragraph=false&probability=false", 8)
+}
+
+// 图片地址转文字，返回带位置信息的识别结果
+func (b *BaiduOcr) ImageUrlToResult(imageUrl string) (result *BodyResultResponse, fileSuffix string, FileSize int, err error) {
+	return b.recognizeImageUrl(transformUrlBaidu, imageUrl, "&detect_direction=false&detect_language=false&paragraph=false&probability=false")
+}
+
 // 图片地址转文字
 func (b *BaiduOcr) ImageUrlToWord(imageUrl string) (word string, fileSuffix string, FileSize int, err error) {
 	suffix, err := getSuffix(imageUrl)
@@ -123,8 +166,6 @@ func (b *BaiduOcr) PdfToWord(filePath string) (word string, fileSuffix string, F
 		return "", "", 0, errors.New("计算文件大小失败！")
 	}
 
-	defer os.Remove(filePath)
-
 	encode := b.getFileContentAsBase64(filePath)
 	contextLen := len(encode)
 	if contextLen/1024/1024 > 8 {
@@ -147,7 +188,13 @@ func (b *BaiduOcr) PdfUrlToWord(pdfUrl string) (word string, fileSuffix string,
 		return "", "", 0, errors.New("获取前缀失败！")
 	}
 
-	filePath, err := saveFile(pdfUrl, suffix)
+	ws, err := workspace.New("")
+	if err != nil {
+		return "", "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(pdfUrl, suffix)
 	if err != nil {
 		return "", "", 0, errors.New("文件保存在本地失败！")
 	}
@@ -172,67 +219,61 @@ func (b *BaiduOcr) PdfUrlToWord(pdfUrl string) (word string, fileSuffix string,
 	return str, suffix, size, nil
 }
 
-// 获取token
+// 获取token，缓存命中时不加锁
 func (b *BaiduOcr) getAccessToken() (token string, err error) {
-
 	md5String, _ := md5ByString(b.apiKey)
 	tokenKey := "kpai:baiduocr:" + md5String
-	token, err = b.cache.Get(tokenKey)
-	if err != nil {
-		fmt.Printf("baidu gettoken redis token, err = %v \n", err)
+	// 缓存读取失败时重新获取
+	if token, _ = b.cache.Get(tokenKey); len(token) > 1 {
+		return token, nil
 	}
-	if len(token) > 1 {
-		fmt.Printf("baidu gettoken redis token is not empty, token 1%v1 \n", token)
+
+	// 加锁后再查一次，其他协程可能已经刷新
+	b.tokenMu.Lock()
+	defer b.tokenMu.Unlock()
+	if token, _ = b.cache.Get(tokenKey); len(token) > 1 {
 		return token, nil
 	}
+	return b.requestAccessToken(tokenKey)
+}
 
+// 请求新的token并写入缓存
+func (b *BaiduOcr) requestAccessToken(tokenKey string) (token string, err error) {
 	url := tokenUrlBaiDu + "?client_id=%s&client_secret=%s&grant_type=client_credentials"
 	url = fmt.Sprintf(url, b.apiKey, b.apiSecret)
 	payload := strings.NewReader(``)
 	client := &http.Client{}
 	req, err := http.NewRequest("POST", url, payload)
 	if err != nil {
-		fmt.Printf("baidu gettoken http.NewRequest, err %v\n", err)
-		return token, err
+		return "", err
 	}
 	req.Header.Add("Content-Type", "application/json")
 	req.Header.Add("Accept", "application/json")
 
 	res, err := client.Do(req)
 	if err != nil {
-		fmt.Printf("baidu gettoken http.NewRequest Do, err %v\n", err)
-		return token, err
+		return "", err
 	}
 	defer res.Body.Close()
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		fmt.Printf("baidu gettoken http.NewRequest ioutil.ReadAll, err %v\n", err)
-		return token, err
+		return "", err
 	}
 
 	var baiDuTokenResponse BaiDuTokenResponse
 	err = json.Unmarshal(body, &baiDuTokenResponse)
 	if err != nil {
-		fmt.Printf("baidu gettoken http.NewRequest json.Unmarshal, body %v ;err %v\n", string(body), err)
-		return token, err
+		return "", err
 	}
 	if len(baiDuTokenResponse.Error) > 1 {
-		fmt.Printf("baidu gettoken http.NewRequest err, ErrorMsg %v, ErrorCode %v \n", baiDuTokenResponse.Error, baiDuTokenResponse.ErrorDescription)
-		return token, err
+		return "", fmt.Errorf("baidu token error, error = %s, msg = %s", baiDuTokenResponse.Error, baiDuTokenResponse.ErrorDescription)
 	}
 
 	token = baiDuTokenResponse.AccessToken
 	if len(token) > 0 {
-		fmt.Printf("baidu translate save token, %v  \n", token)
-		if err != nil {
-			fmt.Printf("baidu gettoken save token, %v  \n", err.Error())
-			return token, err
-		}
-
 		err = b.cache.Set(tokenKey, token, int(baiDuTokenResponse.ExpiresIn))
 		if err != nil {
-			fmt.Printf("baidu gettoken save token Expire,token = %v  err = %v \n", token, err)
 			return token, err
 		}
 	}
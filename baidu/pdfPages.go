@@ -0,0 +1,175 @@
+package baidu
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/bangongyi/toolkits/pdf"
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// pdf页面文字来源
+const (
+	PageSourceText = "text" // 本地文本层提取
+	PageSourceOcr  = "ocr"  // 百度OCR识别
+)
+
+// 页面含图片且文本层有效字符少于该值时，视为扫描页走OCR
+const minTextLayerLen = 10
+
+// pdf单页识别结果
+type PdfPage struct {
+	PageNum int    `json:"page_num"` // 页码，从1开始
+	Source  string `json:"source"`   // 文字来源 text/ocr
+	Text    string `json:"text"`
+}
+
+// pdf按页转文字，有文本层的页面本地提取，扫描页调用OCR
+func (b *BaiduOcr) PdfToPages(filePath string) (pages []PdfPage, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	pages, err = b.pdfPages(filePath)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return pages, suffix, size, nil
+}
+
+// pdf地址按页转文字
+func (b *BaiduOcr) PdfUrlToPages(pdfUrl string) (pages []PdfPage, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(pdfUrl)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return nil, "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(pdfUrl, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	pages, err = b.pdfPages(filePath)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return pages, suffix, size, nil
+}
+
+func (b *BaiduOcr) pdfPages(filePath string) ([]PdfPage, error) {
+	reader, err := pdf.Open(filePath)
+	if err != nil {
+		// 加密或结构损坏的文件无法本地解析，整份走OCR
+		return b.ocrAllPages(filePath)
+	}
+
+	// 只有需要OCR时才编码文件，纯文本pdf不受8M限制
+	var encode string
+	return routePdfPages(reader, func(pageNum int) (string, error) {
+		if encode == "" {
+			if encode, err = b.pdfBase64(filePath); err != nil {
+				return "", err
+			}
+		}
+		text, _, err := b.ocrPdfPage(encode, pageNum)
+		if err != nil {
+			return "", errors.New("pdf文档解析失败！")
+		}
+		return text, nil
+	})
+}
+
+// 本地文本层的页面读取，*pdf.Reader 满足该接口
+type pdfPageReader interface {
+	NumPage() int
+	Page(num int) (pdf.Page, error)
+}
+
+// 逐页选择文本层或OCR，本地解析失败的页面也走OCR
+func routePdfPages(reader pdfPageReader, ocr func(pageNum int) (string, error)) ([]PdfPage, error) {
+	var pages []PdfPage
+	for i := 1; i <= reader.NumPage(); i++ {
+		page, err := reader.Page(i)
+		if err == nil && !needOcr(page) {
+			pages = append(pages, PdfPage{PageNum: i, Source: PageSourceText, Text: page.Text})
+			continue
+		}
+		text, err := ocr(i)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, PdfPage{PageNum: i, Source: PageSourceOcr, Text: text})
+	}
+	return pages, nil
+}
+
+func needOcr(page pdf.Page) bool {
+	if !page.HasText {
+		return true
+	}
+	return page.HasImage && page.TextLen() < minTextLayerLen
+}
+
+// 逐页OCR，总页数取自第一页的识别结果
+func (b *BaiduOcr) ocrAllPages(filePath string) ([]PdfPage, error) {
+	encode, err := b.pdfBase64(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var pages []PdfPage
+	total := 1
+	for i := 1; i <= total; i++ {
+		text, pageCount, err := b.ocrPdfPage(encode, i)
+		if err != nil {
+			return nil, errors.New("pdf文档解析失败！")
+		}
+		if i == 1 && pageCount > 1 {
+			total = pageCount
+		}
+		pages = append(pages, PdfPage{PageNum: i, Source: PageSourceOcr, Text: text})
+	}
+	return pages, nil
+}
+
+func (b *BaiduOcr) pdfBase64(filePath string) (string, error) {
+	encode := b.getFileContentAsBase64(filePath)
+	if encode == "" {
+		return "", errors.New("读取文件失败！")
+	}
+	if len(encode)/1024/1024 > 8 {
+		return "", errors.New("文件大小不能大于8M")
+	}
+	return encode, nil
+}
+
+// 识别pdf指定页，返回该页文字和文档总页数
+func (b *BaiduOcr) ocrPdfPage(encode string, pageNum int) (word string, pageCount int, err error) {
+	payload := strings.NewReader("pdf_file=" + url.QueryEscape(encode) + "&pdf_file_num=" + strconv.Itoa(pageNum) +
+		"&detect_direction=false&detect_language=false&paragraph=false&probability=false")
+	resBody, err := b.ocrRequest(transformUrlBaidu, payload)
+	if err != nil {
+		return "", 0, err
+	}
+
+	count, _ := strconv.Atoi(resBody.PdfFileSize.String())
+	return resBody.Text(), count, nil
+}
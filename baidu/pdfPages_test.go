@@ -0,0 +1,48 @@
+package baidu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/bangongyi/toolkits/pdf"
+)
+
+// 按页码返回固定结果的文本层，缺少的页码解析失败
+type fakePages struct {
+	n     int
+	pages map[int]pdf.Page
+}
+
+func (f fakePages) NumPage() int { return f.n }
+
+func (f fakePages) Page(num int) (pdf.Page, error) {
+	page, ok := f.pages[num]
+	if !ok {
+		return pdf.Page{}, errors.New("页面解析失败！")
+	}
+	return page, nil
+}
+
+func TestRoutePdfPages(t *testing.T) {
+	// 第 2 页本地解析失败，第 3 页是扫描页
+	reader := fakePages{n: 4, pages: map[int]pdf.Page{
+		1: {Number: 1, Text: "正文内容足够长的一页文字", HasText: true},
+		3: {Number: 3, HasImage: true},
+		4: {Number: 4, Text: "第四页同样有足够的文字", HasText: true},
+	}}
+
+	var ocrPages []int
+	pages, err := routePdfPages(reader, func(pageNum int) (string, error) {
+		ocrPages = append(ocrPages, pageNum)
+		return "ocr", nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 4 || len(ocrPages) != 2 || ocrPages[0] != 2 || ocrPages[1] != 3 {
+		t.Fatalf("pages = %+v, ocr = %v", pages, ocrPages)
+	}
+	if pages[1].Source != PageSourceOcr || pages[1].Text != "ocr" || pages[3].Source != PageSourceText {
+		t.Fatalf("pages = %+v", pages)
+	}
+}
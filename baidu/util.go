@@ -9,31 +9,48 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"os"
+	"strconv"
 	"strings"
 )
 
 func (b *BaiduOcr) commonFun(payload *strings.Reader) (word string, err error) {
-	token, err := b.getAccessToken()
+	resBody, err := b.ocrRequest(transformUrlBaidu, payload)
 	if err != nil {
 		return "", err
 	}
 
-	requestUrl := fmt.Sprintf(transformUrlBaidu, token)
+	var str string
+	for _, val := range resBody.WordsResult {
+		str += val.Words + ","
+	}
+	str = strings.TrimRight(str, ",")
+
+	return str, nil
+}
+
+// 调用文字识别接口，返回原始识别结果
+func (b *BaiduOcr) ocrRequest(apiUrl string, payload *strings.Reader) (*BodyResultResponse, error) {
+	token, err := b.getAccessToken()
+	if err != nil {
+		return nil, err
+	}
+
+	requestUrl := fmt.Sprintf(apiUrl, token)
 
 	client := &http.Client{}
 	req, err := http.NewRequest("POST", requestUrl, payload)
 
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Add("Accept", "application/json")
 
 	res, err := client.Do(req)
 	if err != nil {
-		fmt.Println(err)
-		return
+		return nil, err
 	}
 	defer func(Body io.ReadCloser) {
 		err := Body.Close()
@@ -44,63 +61,70 @@ func (b *BaiduOcr) commonFun(payload *strings.Reader) (word string, err error) {
 
 	body, err := ioutil.ReadAll(res.Body)
 	if err != nil {
-		return
+		return nil, err
 	}
 	resBody1 := BodyResultResponse{}
 	err = json.Unmarshal(body, &resBody1)
 	if err != nil {
-		return
+		return nil, err
 	}
-
-	var str string
-	for _, val := range resBody1.WordsResult {
-		str += val.Words + ","
+	if resBody1.ErrorCode != 0 {
+		return nil, fmt.Errorf("baidu ocr error, code = %d, msg = %s", resBody1.ErrorCode, resBody1.ErrorMsg)
 	}
-	str = strings.TrimRight(str, ",")
 
-	return str, nil
+	return &resBody1, nil
 }
 
-func saveFile(url string, suffix string) (string, error) {
-	byString, err := md5ByString(url)
+// 识别本地图片，maxSize 为编码后的大小上限（M），各接口不同
+func (b *BaiduOcr) recognizeImage(apiUrl string, filePath string, params string, maxSize int) (*BodyResultResponse, string, int, error) {
+	suffix, err := getSuffix(filePath)
 	if err != nil {
-		return "", err
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
 	}
-	targetName := "temporary" + byString + "." + suffix
 
-	// 发起GET请求
-	response, err := http.Get(url)
+	encode := b.getFileContentAsBase64(filePath)
+	if len(encode)/1024/1024 > maxSize {
+		return nil, "", 0, errors.New("文件大小不能大于" + strconv.Itoa(maxSize) + "M！")
+	}
+	payload := strings.NewReader("image=" + url.QueryEscape(encode) + params)
+	res, err := b.ocrRequest(apiUrl, payload)
 	if err != nil {
-		return "", err
+		return nil, "", 0, errors.New("图片识别失败！")
 	}
-	defer response.Body.Close()
+	return res, suffix, size, nil
+}
 
-	// 创建临时文件
-	tmpFile, err := ioutil.TempFile("/tmp", "tempfile")
+// 识别图片地址
+func (b *BaiduOcr) recognizeImageUrl(apiUrl string, imageUrl string, params string) (*BodyResultResponse, string, int, error) {
+	suffix, err := getSuffix(imageUrl)
 	if err != nil {
-		return "", err
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	if len(imageUrl) > 1024 {
+		return nil, "", 0, errors.New("图片地址不能超过 1024 个字节")
 	}
-	defer tmpFile.Close()
 
-	// 将HTTP响应的内容写入临时文件
-	_, err = io.Copy(tmpFile, response.Body)
+	size, err := countImgSize(imageUrl)
 	if err != nil {
-		return "", errors.New("写入临时文件时出错！")
+		return nil, "", 0, errors.New("获取图片大小失败！")
 	}
 
-	// 重命名临时文件为目标文件名并保存到本地/tmp目录下
-	err = os.Rename(tmpFile.Name(), "/tmp/"+targetName)
+	payload := strings.NewReader("url=" + url.QueryEscape(imageUrl) + params)
+	res, err := b.ocrRequest(apiUrl, payload)
 	if err != nil {
-		return "", errors.New("重命名临时文件失败！")
+		return nil, "", 0, errors.New("图片识别失败！")
 	}
-	return "/tmp/" + targetName, nil
+	return res, suffix, size, nil
 }
 
 // base64编码后进行urlEncode
 func (b *BaiduOcr) getFileContentAsBase64(path string) string {
 	srcByte, err := ioutil.ReadFile(path)
 	if err != nil {
-		fmt.Println(err)
 		return ""
 	}
 	return base64.StdEncoding.EncodeToString(srcByte)
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type cacheItem struct {
+	Value    string `json:"value"`
+	ExpireAt int64  `json:"expire_at"`
+}
+
+// 基于本地文件的 token 缓存，实现 baidu.Cache，多次运行之间复用 access_token
+type fileCache struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newFileCache(path string) *fileCache {
+	return &fileCache{path: path}
+}
+
+func defaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "toolkits-ocr", "token.json")
+}
+
+func (c *fileCache) Get(key string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items, err := c.load()
+	if err != nil {
+		return "", err
+	}
+	// 未命中返回空字符串，由调用方重新获取 token
+	item, ok := items[key]
+	if !ok || (item.ExpireAt > 0 && item.ExpireAt <= time.Now().Unix()) {
+		return "", nil
+	}
+	return item.Value, nil
+}
+
+func (c *fileCache) Set(key string, value string, expires int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	items, err := c.load()
+	if err != nil {
+		items = map[string]cacheItem{}
+	}
+	item := cacheItem{Value: value}
+	if expires > 0 {
+		// 提前一分钟过期，避免临界时刻使用失效的 token
+		item.ExpireAt = time.Now().Unix() + int64(expires) - 60
+	}
+	items[key] = item
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	// 先写临时文件再重命名，避免并发进程读到半个文件；临时文件名随机，并发写入时互不覆盖
+	f, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return nil
+}
+
+func (c *fileCache) load() (map[string]cacheItem, error) {
+	items := map[string]cacheItem{}
+	data, err := ioutil.ReadFile(c.path)
+	if os.IsNotExist(err) {
+		return items, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -0,0 +1,321 @@
+// toolkits-ocr 是 baidu.BaiduOcr 的命令行封装，用于临时的批量识别任务。
+//
+// 用法：
+//
+//	toolkits-ocr [flags] <文件|目录|URL>...
+//
+// 凭证优先读取 -config 指定的配置文件（json/yaml/toml，格式同 baidu.Config），
+// 其次读取环境变量 BAIDU_OCR_API_KEY / BAIDU_OCR_API_SECRET。
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/bangongyi/toolkits/baidu"
+	"github.com/zeromicro/go-zero/core/conf"
+)
+
+var imageSuffixes = map[string]bool{
+	"jpg": true, "jpeg": true, "png": true, "bmp": true, "tif": true, "tiff": true,
+}
+
+type options struct {
+	configPath  string
+	cachePath   string
+	format      string
+	output      string
+	mode        string
+	recursive   bool
+	concurrency int
+}
+
+// 单个输入的识别结果
+type result struct {
+	Source string          `json:"source"`
+	Text   string          `json:"text,omitempty"`
+	Latex  []string        `json:"latex,omitempty"`
+	Pages  []baidu.PdfPage `json:"pages,omitempty"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func main() {
+	var opts options
+	flag.StringVar(&opts.configPath, "config", "", "凭证配置文件（json/yaml/toml，字段 ApiKey/ApiSecret）")
+	flag.StringVar(&opts.cachePath, "token-cache", defaultCachePath(), "access_token 缓存文件")
+	flag.StringVar(&opts.format, "format", "text", "输出格式：text/json/markdown")
+	flag.StringVar(&opts.output, "o", "", "输出文件，默认标准输出")
+	flag.StringVar(&opts.mode, "mode", "general", "图片识别模式：general/handwriting/formula")
+	flag.BoolVar(&opts.recursive, "r", false, "递归处理子目录")
+	flag.IntVar(&opts.concurrency, "c", 4, "并发数")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "用法: %s [flags] <文件|目录|URL>...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(opts, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, "toolkits-ocr:", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts options, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return errors.New("缺少输入")
+	}
+	switch opts.format {
+	case "text", "json", "markdown":
+	default:
+		return fmt.Errorf("不支持的输出格式 %q", opts.format)
+	}
+	switch opts.mode {
+	case "general", "handwriting", "formula":
+	default:
+		return fmt.Errorf("不支持的识别模式 %q", opts.mode)
+	}
+	if opts.concurrency < 1 {
+		opts.concurrency = 1
+	}
+
+	inputs, err := collectInputs(args, opts.recursive)
+	if err != nil {
+		return err
+	}
+	if len(inputs) == 0 {
+		return errors.New("没有可识别的文件")
+	}
+
+	c, err := loadConfig(opts.configPath)
+	if err != nil {
+		return err
+	}
+	// 纯文本 pdf 不需要访问百度，首次识别图片时再获取 token
+	c.Lazy = true
+
+	out := os.Stdout
+	if opts.output != "" {
+		f, err := os.Create(opts.output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	ocr, err := baidu.NewBaiduOcrWithConfig(c, newFileCache(opts.cachePath))
+	if err != nil {
+		return fmt.Errorf("初始化百度OCR失败: %v", err)
+	}
+
+	results := recognizeAll(ocr, inputs, opts)
+	if err := writeResults(out, results, opts.format); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if r.Error != "" {
+			return errors.New("部分输入识别失败")
+		}
+	}
+	return nil
+}
+
+func loadConfig(configPath string) (baidu.Config, error) {
+	if configPath == "" {
+		return baidu.LoadConfigFromEnv()
+	}
+	var c baidu.Config
+	if err := conf.Load(configPath, &c); err != nil {
+		return c, fmt.Errorf("加载配置文件失败: %v", err)
+	}
+	return c, c.Validate()
+}
+
+func isUrl(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+func suffixOf(path string) string {
+	if isUrl(path) {
+		if i := strings.IndexAny(path, "?#"); i >= 0 {
+			path = path[:i]
+		}
+	}
+	return strings.ToLower(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+func supported(path string) bool {
+	suffix := suffixOf(path)
+	return suffix == "pdf" || imageSuffixes[suffix]
+}
+
+// 展开目录，保持命令行给出的顺序
+func collectInputs(args []string, recursive bool) ([]string, error) {
+	var inputs []string
+	for _, arg := range args {
+		if isUrl(arg) {
+			inputs = append(inputs, arg)
+			continue
+		}
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			inputs = append(inputs, arg)
+			continue
+		}
+		err = filepath.Walk(arg, func(path string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if fi.IsDir() {
+				if path != arg && !recursive {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if supported(path) {
+				inputs = append(inputs, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return inputs, nil
+}
+
+func recognizeAll(ocr *baidu.BaiduOcr, inputs []string, opts options) []result {
+	results := make([]result, len(inputs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < opts.concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = recognize(ocr, inputs[i], opts.mode)
+				if results[i].Error != "" {
+					fmt.Fprintf(os.Stderr, "%s: %s\n", inputs[i], results[i].Error)
+				}
+			}
+		}()
+	}
+	for i := range inputs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return results
+}
+
+func recognize(ocr *baidu.BaiduOcr, input string, mode string) result {
+	res := result{Source: input}
+	suffix := suffixOf(input)
+	remote := isUrl(input)
+
+	if suffix == "pdf" {
+		var pages []baidu.PdfPage
+		var err error
+		if remote {
+			pages, _, _, err = ocr.PdfUrlToPages(input)
+		} else {
+			pages, _, _, err = ocr.PdfToPages(input)
+		}
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.Pages = pages
+		texts := make([]string, 0, len(pages))
+		for _, p := range pages {
+			texts = append(texts, p.Text)
+		}
+		res.Text = strings.Join(texts, "\n\n")
+		return res
+	}
+	if !imageSuffixes[suffix] {
+		res.Error = "不支持的文件类型"
+		return res
+	}
+
+	var body *baidu.BodyResultResponse
+	var err error
+	switch {
+	case mode == "handwriting" && remote:
+		body, _, _, err = ocr.HandwritingUrlToResult(input)
+	case mode == "handwriting":
+		body, _, _, err = ocr.HandwritingToResult(input)
+	case mode == "formula" && remote:
+		body, _, _, err = ocr.FormulaUrlToResult(input)
+	case mode == "formula":
+		body, _, _, err = ocr.FormulaToResult(input)
+	case remote:
+		body, _, _, err = ocr.ImageUrlToResult(input)
+	default:
+		body, _, _, err = ocr.ImageToResult(input)
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Text = body.Text()
+	res.Latex = body.Latex()
+	return res
+}
+
+func writeResults(w io.Writer, results []result, format string) error {
+	switch format {
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		return enc.Encode(results)
+	case "markdown":
+		for _, r := range results {
+			fmt.Fprintf(w, "## %s\n\n", r.Source)
+			if r.Error != "" {
+				fmt.Fprintf(w, "> 识别失败：%s\n\n", r.Error)
+				continue
+			}
+			if len(r.Pages) > 0 {
+				for _, p := range r.Pages {
+					fmt.Fprintf(w, "### 第 %d 页（%s）\n\n%s\n\n", p.PageNum, p.Source, p.Text)
+				}
+				continue
+			}
+			fmt.Fprintf(w, "%s\n\n", r.Text)
+			for _, latex := range r.Latex {
+				fmt.Fprintf(w, "$$\n%s\n$$\n\n", latex)
+			}
+		}
+	default:
+		for i, r := range results {
+			if len(results) > 1 {
+				if i > 0 {
+					fmt.Fprintln(w)
+				}
+				fmt.Fprintf(w, "==> %s <==\n", r.Source)
+			}
+			if r.Error != "" {
+				fmt.Fprintf(w, "识别失败：%s\n", r.Error)
+				continue
+			}
+			fmt.Fprintln(w, r.Text)
+			for _, latex := range r.Latex {
+				fmt.Fprintln(w, latex)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,186 @@
+package document
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// 页面类型
+const (
+	PageKindPage  = "page"  // 文档页，word 等流式文档整体为一页
+	PageKindSlide = "slide" // 幻灯片
+	PageKindSheet = "sheet" // 工作表
+)
+
+// 块类型
+const (
+	BlockParagraph = "paragraph"
+	BlockHeading   = "heading"
+	BlockListItem  = "list_item"
+	BlockTable     = "table"
+	BlockImage     = "image"
+	BlockFormula   = "formula" // 公式，Text 为 LaTeX
+)
+
+// 页面内容来源
+const (
+	OriginText = "text" // 从文件中直接提取
+	OriginOcr  = "ocr"  // 文字识别
+)
+
+// 提取结果的通用结构，各格式的内容统一为 页 -> 块，office 各格式和百度OCR的结果都转为该结构
+type Document struct {
+	Source Source `json:"source"`
+	Meta   Meta   `json:"meta"`
+	Pages  []Page `json:"pages"`
+}
+
+// 文档属性，取自文件自带的属性信息，统计数字为保存文件的程序记录的值
+type Meta struct {
+	Title          string     `json:"title,omitempty"`
+	Subject        string     `json:"subject,omitempty"`
+	Author         string     `json:"author,omitempty"`
+	Keywords       string     `json:"keywords,omitempty"`
+	Description    string     `json:"description,omitempty"`
+	LastModifiedBy string     `json:"last_modified_by,omitempty"`
+	Created        *time.Time `json:"created,omitempty"`
+	Modified       *time.Time `json:"modified,omitempty"`
+	Application    string     `json:"application,omitempty"` // 保存文件的程序
+	Company        string     `json:"company,omitempty"`
+	Pages          int        `json:"pages,omitempty"`
+	Words          int        `json:"words,omitempty"`
+	Characters     int        `json:"characters,omitempty"`
+	Slides         int        `json:"slides,omitempty"`
+}
+
+// 文件来源
+type Source struct {
+	Name   string `json:"name,omitempty"`   // 文件名
+	Path   string `json:"path,omitempty"`   // 本地路径
+	URL    string `json:"url,omitempty"`    // 远程地址
+	Suffix string `json:"suffix,omitempty"` // 文件名中的后缀，可能与实际格式不同
+	Format string `json:"format"`           // 按文件内容识别的格式
+	Size   int    `json:"size"`             // 文件大小，单位字节
+}
+
+// 页、幻灯片或工作表
+type Page struct {
+	Kind   string   `json:"kind"`             // 见 PageKind* 常量
+	Number int      `json:"number"`           // 从 1 开始
+	Origin string   `json:"origin,omitempty"` // 见 Origin* 常量，为空时同 OriginText
+	Title  string   `json:"title,omitempty"`
+	Hidden bool     `json:"hidden,omitempty"`
+	Blocks []Block  `json:"blocks"`
+	Notes  []string `json:"notes,omitempty"` // 幻灯片备注
+}
+
+// 段落、标题、列表项、表格、图片或公式
+type Block struct {
+	Type  string `json:"type"` // 见 Block* 常量
+	Text  string `json:"text,omitempty"`
+	Level int    `json:"level,omitempty"` // 标题级别从 1 开始，列表层级从 0 开始
+	Table *Table `json:"table,omitempty"`
+	Image *Image `json:"image,omitempty"`
+	Box   *Box   `json:"box,omitempty"` // OCR 识别出的文字在图片中的位置
+}
+
+// 表格，合并区域只在起始单元格保留内容
+type Table struct {
+	Header []string   `json:"header,omitempty"`
+	Rows   [][]string `json:"rows"`
+}
+
+// 图片，Block.Text 为识别出的文字
+type Image struct {
+	Name        string `json:"name,omitempty"` // 图片在文件中的名称
+	ContentType string `json:"content_type,omitempty"`
+	Anchor      string `json:"anchor,omitempty"` // excel 中图片左上角所在的单元格
+}
+
+// 矩形区域，单位像素
+type Box struct {
+	Left   int `json:"left"`
+	Top    int `json:"top"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// 按行拆分为段落，跳过空行
+func TextBlocks(text string) []Block {
+	list := []Block{}
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			list = append(list, Block{Type: BlockParagraph, Text: line})
+		}
+	}
+	return list
+}
+
+// 序列化为 JSON
+func (d *Document) JSON() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+// 从 JSON 还原，页面和块的类型必须有值
+func FromJSON(data []byte) (*Document, error) {
+	var d Document
+	if err := json.Unmarshal(data, &d); err != nil {
+		return nil, errors.New("文档格式错误！")
+	}
+	for _, p := range d.Pages {
+		if p.Kind == "" {
+			return nil, errors.New("页面缺少类型！")
+		}
+		for _, b := range p.Blocks {
+			if b.Type == "" {
+				return nil, errors.New("内容块缺少类型！")
+			}
+		}
+	}
+	return &d, nil
+}
+
+// 纯文本，页之间空一行
+func (d *Document) Text() string {
+	parts := make([]string, 0, len(d.Pages))
+	for _, p := range d.Pages {
+		if text := p.Text(); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// 单页的纯文本：标题、各块、备注依次换行
+func (p Page) Text() string {
+	var lines []string
+	if p.Title != "" {
+		lines = append(lines, p.Title)
+	}
+	for _, b := range p.Blocks {
+		if text := b.Plain(); text != "" {
+			lines = append(lines, text)
+		}
+	}
+	if len(p.Notes) > 0 {
+		lines = append(lines, "备注："+strings.Join(p.Notes, "\n"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 块的纯文本，表格每行一行、单元格以制表符分隔
+func (b Block) Plain() string {
+	if b.Table == nil {
+		return b.Text
+	}
+	lines := make([]string, 0, len(b.Table.Rows)+1)
+	if len(b.Table.Header) > 0 {
+		lines = append(lines, strings.Join(b.Table.Header, "\t"))
+	}
+	for _, row := range b.Table.Rows {
+		lines = append(lines, strings.Join(row, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -2,27 +2,32 @@ module github.com/bangongyi/toolkits
 
 go 1.20
 
-require github.com/tealeg/xlsx v1.0.5
+require (
+	github.com/jinzhu/copier v0.4.0
+	github.com/pkg/errors v0.9.1
+	github.com/richardlehane/mscfb v1.0.4
+	github.com/richardlehane/msoleps v1.0.3
+	github.com/tealeg/xlsx v1.0.5
+	github.com/zeromicro/go-zero v1.6.1
+	golang.org/x/net v0.19.0
+	golang.org/x/text v0.14.0
+	google.golang.org/grpc v1.60.1
+)
 
 require (
 	baliance.com/gooxml v1.0.1 // indirect
 	github.com/fatih/color v1.16.0 // indirect
 	github.com/golang/protobuf v1.5.3 // indirect
-	github.com/jinzhu/copier v0.4.0 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
 	github.com/pelletier/go-toml/v2 v2.1.0 // indirect
-	github.com/pkg/errors v0.9.1 // indirect
-	github.com/richardlehane/msoleps v1.0.3 // indirect
 	github.com/spaolacci/murmur3 v1.1.0 // indirect
 	github.com/unidoc/unioffice v1.29.1 // indirect
-	github.com/zeromicro/go-zero v1.6.1 // indirect
 	go.opentelemetry.io/otel v1.19.0 // indirect
 	go.opentelemetry.io/otel/trace v1.19.0 // indirect
 	go.uber.org/automaxprocs v1.5.3 // indirect
 	golang.org/x/sys v0.15.0 // indirect
 	google.golang.org/genproto/googleapis/rpc v0.0.0-20231016165738-49dd2c1f3d0b // indirect
-	google.golang.org/grpc v1.60.1 // indirect
 	google.golang.org/protobuf v1.31.1-0.20231027082548-f4a6c1f6e5c1 // indirect
 	gopkg.in/yaml.v2 v2.4.0 // indirect
 )
@@ -0,0 +1,229 @@
+package office
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// csv读取选项
+type CsvOptions struct {
+	Delimiter rune   // 分隔符，为 0 时自动检测
+	Encoding  string // 文本编码，见 Encoding* 常量，为空时自动检测
+	NoHeader  bool   // 没有表头，第一行也作为数据行
+}
+
+// 候选分隔符，按优先级排列
+var csvDelimiters = []rune{',', '\t', ';', '|'}
+
+// csv/tsv文件转工作簿，工作簿只有一个以文件名命名的工作表
+func CsvToWorkbook(filePath string) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	return CsvToWorkbookWithOptions(filePath, CsvOptions{})
+}
+
+// csv/tsv地址文件转工作簿
+func CsvUrlToWorkbook(url string) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	return CsvUrlToWorkbookWithOptions(url, CsvOptions{})
+}
+
+// csv/tsv文件按选项转工作簿
+func CsvToWorkbookWithOptions(filePath string, opts CsvOptions) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	book, err = parseCsv(filePath, csvSheetName(filepath.Base(filePath)), suffix, opts)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return book, suffix, size, nil
+}
+
+// csv/tsv地址文件按选项转工作簿
+func CsvUrlToWorkbookWithOptions(url string, opts CsvOptions) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return nil, "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	book, err = parseCsv(filePath, csvSheetName(path.Base(url)), suffix, opts)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return book, suffix, size, nil
+}
+
+func isCsvSuffix(suffix string) bool {
+	switch strings.ToLower(suffix) {
+	case "csv", "tsv", "tab":
+		return true
+	}
+	return false
+}
+
+// 去掉扩展名作为工作表名
+func csvSheetName(base string) string {
+	if i := strings.IndexAny(base, "?#"); i >= 0 {
+		base = base[:i]
+	}
+	return strings.TrimSuffix(base, path.Ext(base))
+}
+
+func parseCsv(filePath string, name string, suffix string, opts CsvOptions) (*Workbook, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	text, _, err := decodeText(data, opts.Encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = sniffDelimiter(text, suffix)
+	}
+	r := csv.NewReader(strings.NewReader(text))
+	r.Comma = delimiter
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+
+	s := Sheet{Name: name, Rows: []Row{}}
+	headerDone := opts.NoHeader
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, errors.New("解析csv失败！")
+		}
+		line, _ := r.FieldPos(0)
+		cells := make([]Cell, 0, len(record))
+		for _, v := range record {
+			c := Cell{Type: CellTypeString, Text: v, Value: v}
+			if v == "" {
+				c.Type = CellTypeEmpty
+			}
+			cells = append(cells, c)
+		}
+		if isEmptyRow(cells) {
+			continue
+		}
+		if !headerDone {
+			for _, c := range cells {
+				s.Headers = append(s.Headers, strings.TrimSpace(c.Text))
+			}
+			headerDone = true
+			continue
+		}
+		s.Rows = append(s.Rows, Row{Index: line, Cells: cells})
+	}
+	return &Workbook{Sheets: []Sheet{s}}, nil
+}
+
+// 按前几行检测分隔符：各行出现次数一致且最多的候选优先，检测不出时 tsv 用制表符，其余用逗号
+func sniffDelimiter(text string, suffix string) rune {
+	lines := csvSampleLines(text, 20)
+	best, bestCount := rune(0), 0
+	for _, d := range csvDelimiters {
+		count, consistent := -1, true
+		for _, line := range lines {
+			n := countDelimiter(line, d)
+			if count == -1 {
+				count = n
+			} else if n != count {
+				consistent = false
+				break
+			}
+		}
+		if consistent && count > bestCount {
+			best, bestCount = d, count
+		}
+	}
+	if best != 0 {
+		return best
+	}
+	// 各行不一致（如有多行字段）时取第一行出现最多的
+	if len(lines) > 0 {
+		for _, d := range csvDelimiters {
+			if n := countDelimiter(lines[0], d); n > bestCount {
+				best, bestCount = d, n
+			}
+		}
+	}
+	if best != 0 {
+		return best
+	}
+	if strings.ToLower(suffix) != "csv" {
+		return '\t'
+	}
+	return ','
+}
+
+// 取前 n 个非空行，引号内的换行不作为行结束
+func csvSampleLines(text string, n int) []string {
+	var lines []string
+	var b strings.Builder
+	quoted := false
+	for _, ch := range text {
+		switch {
+		case ch == '"':
+			quoted = !quoted
+		case (ch == '\n' || ch == '\r') && !quoted:
+			if line := b.String(); strings.TrimSpace(line) != "" {
+				lines = append(lines, line)
+				if len(lines) == n {
+					return lines
+				}
+			}
+			b.Reset()
+			continue
+		}
+		b.WriteRune(ch)
+	}
+	if line := b.String(); strings.TrimSpace(line) != "" && len(lines) < n {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// 引号外的分隔符个数
+func countDelimiter(line string, d rune) int {
+	n, quoted := 0, false
+	for _, ch := range line {
+		if ch == '"' {
+			quoted = !quoted
+		} else if ch == d && !quoted {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,105 @@
+package office
+
+import (
+	"github.com/bangongyi/toolkits/document"
+)
+
+// 转为通用文档结构，word 没有分页信息，整篇为一页；有章节时每章一页
+func (d *WordDocument) Document() *document.Document {
+	if len(d.Chapters) == 0 {
+		page := document.Page{Kind: document.PageKindPage, Number: 1, Blocks: wordBlocks(d.Blocks)}
+		return &document.Document{Pages: []document.Page{page}}
+	}
+	pages := make([]document.Page, 0, len(d.Chapters))
+	for i, c := range d.Chapters {
+		blocks := d.Blocks[c.Start:c.End]
+		// 章节开头与章节名相同的标题已作为页标题
+		if len(blocks) > 0 && blocks[0].Paragraph != nil && blocks[0].Paragraph.HeadingLevel > 0 && blocks[0].Paragraph.Text == c.Title {
+			blocks = blocks[1:]
+		}
+		pages = append(pages, document.Page{
+			Kind:   document.PageKindPage,
+			Number: i + 1,
+			Title:  c.Title,
+			Blocks: wordBlocks(blocks),
+		})
+	}
+	return &document.Document{Pages: pages}
+}
+
+func wordBlocks(blocks []WordBlock) []document.Block {
+	list := make([]document.Block, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Table != nil {
+			list = append(list, document.Block{Type: document.BlockTable, Table: wordTable(b.Table)})
+			continue
+		}
+		p := b.Paragraph
+		switch {
+		case p.HeadingLevel > 0:
+			list = append(list, document.Block{Type: document.BlockHeading, Text: p.Text, Level: p.HeadingLevel})
+		case p.IsList:
+			text := p.Text
+			if p.Numbering != "" {
+				text = p.Numbering + " " + text
+			}
+			list = append(list, document.Block{Type: document.BlockListItem, Text: text, Level: p.ListLevel})
+		default:
+			list = append(list, document.Block{Type: document.BlockParagraph, Text: p.Text})
+		}
+	}
+	return list
+}
+
+func wordTable(t *WordTable) *document.Table {
+	table := &document.Table{Rows: make([][]string, 0, len(t.Rows))}
+	for _, row := range t.Rows {
+		cells := make([]string, t.Cols)
+		for _, cell := range row.Cells {
+			if !cell.Merged && cell.Col < t.Cols {
+				cells[cell.Col] = cell.Text
+			}
+		}
+		table.Rows = append(table.Rows, cells)
+	}
+	return table
+}
+
+// 转为通用文档结构，每个工作表一页，内容为一个表格
+func (w *Workbook) Document() *document.Document {
+	pages := make([]document.Page, 0, len(w.Sheets))
+	for i, s := range w.Sheets {
+		table := &document.Table{Header: s.Headers, Rows: make([][]string, 0, len(s.Rows))}
+		for _, row := range s.Rows {
+			cells := make([]string, 0, len(row.Cells))
+			for _, c := range row.Cells {
+				cells = append(cells, c.Text)
+			}
+			table.Rows = append(table.Rows, cells)
+		}
+		pages = append(pages, document.Page{
+			Kind:   document.PageKindSheet,
+			Number: i + 1,
+			Title:  s.Name,
+			Hidden: s.Hidden,
+			Blocks: []document.Block{{Type: document.BlockTable, Table: table}},
+		})
+	}
+	return &document.Document{Pages: pages}
+}
+
+// 转为通用文档结构，每张幻灯片一页
+func (p *Presentation) Document() *document.Document {
+	pages := make([]document.Page, 0, len(p.Slides))
+	for _, s := range p.Slides {
+		pages = append(pages, document.Page{
+			Kind:   document.PageKindSlide,
+			Number: s.Number,
+			Title:  s.Title,
+			Hidden: s.Hidden,
+			Blocks: wordBlocks(s.Body),
+			Notes:  s.Notes,
+		})
+	}
+	return &document.Document{Pages: pages}
+}
@@ -0,0 +1,168 @@
+package office
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// 文本编码
+const (
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingGBK     = "gbk"
+	EncodingGB18030 = "gb18030"
+	EncodingBig5    = "big5"
+)
+
+var (
+	bomUTF8    = []byte{0xef, 0xbb, 0xbf}
+	bomUTF16LE = []byte{0xff, 0xfe}
+	bomUTF16BE = []byte{0xfe, 0xff}
+)
+
+// 检测文本编码：先看 BOM，再看 UTF-16 的零字节分布，合法 UTF-8 按 UTF-8，
+// 否则在 GB18030（兼容 GBK）和 Big5 中取常用字更多的一个
+func detectEncoding(data []byte) string {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return EncodingUTF16BE
+	}
+	if enc := sniffUTF16(data); enc != "" {
+		return enc
+	}
+	if utf8.Valid(data) {
+		return EncodingUTF8
+	}
+	return sniffChinese(data)
+}
+
+// 常用汉字，含简繁两种写法
+var commonHanzi = func() map[rune]bool {
+	m := map[rune]bool{}
+	for _, r := range "的一是不了人在有我他这這中大来來上个個们們到说說时時为為国國和地要就出会會也你对對生能而子那得于於着著下自之年过過发發后後作里裡用道行所然家种種事成方多经經么麼去法学學如都同现現当當没沒动動面起看定天分还還进進好小部其些主样樣理心她本前开開但因只从從想实實日" {
+		m[r] = true
+	}
+	return m
+}()
+
+// 分别按 GB18030 和 Big5 解码前 4KB，常用字多、无效字符少的为实际编码
+func sniffChinese(data []byte) string {
+	if len(data) > 4096 {
+		data = data[:4096]
+	}
+	score := func(enc encoding.Encoding) int {
+		out, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return -1
+		}
+		n := 0
+		for _, r := range string(out) {
+			switch {
+			case r == utf8.RuneError:
+				n -= 2
+			case commonHanzi[r]:
+				n++
+			}
+		}
+		return n
+	}
+	if score(traditionalchinese.Big5) > score(simplifiedchinese.GB18030) {
+		return EncodingBig5
+	}
+	return EncodingGB18030
+}
+
+// 没有 BOM 的 UTF-16：ASCII 字符的高字节为 0，集中在奇数位（LE）或偶数位（BE）
+func sniffUTF16(data []byte) string {
+	n := len(data)
+	if n > 4096 {
+		n = 4096
+	}
+	n -= n % 2
+	if n < 4 {
+		return ""
+	}
+	even, odd := 0, 0
+	for i := 0; i < n; i += 2 {
+		if data[i] == 0 {
+			even++
+		}
+		if data[i+1] == 0 {
+			odd++
+		}
+	}
+	half := n / 2
+	switch {
+	case odd*10 > half*3 && even*10 < half:
+		return EncodingUTF16LE
+	case even*10 > half*3 && odd*10 < half:
+		return EncodingUTF16BE
+	}
+	return ""
+}
+
+func textEncoding(name string) (encoding.Encoding, error) {
+	switch strings.ToLower(strings.ReplaceAll(name, "_", "-")) {
+	case EncodingUTF8, "utf8":
+		return unicode.UTF8BOM, nil
+	case EncodingUTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM), nil
+	case EncodingUTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM), nil
+	case EncodingGBK, "gb2312", "cp936":
+		return simplifiedchinese.GBK, nil
+	case EncodingGB18030:
+		return simplifiedchinese.GB18030, nil
+	case EncodingBig5, "big-5", "cp950":
+		return traditionalchinese.Big5, nil
+	}
+	return nil, errors.New("不支持的编码 " + name + "！")
+}
+
+// 按编码转为 UTF-8 文本并去掉 BOM，charset 为空时自动检测，返回实际使用的编码
+func decodeText(data []byte, charset string) (text string, used string, err error) {
+	if charset == "" {
+		charset = detectEncoding(data)
+	}
+	enc, err := textEncoding(charset)
+	if err != nil {
+		return "", "", err
+	}
+	out, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", "", errors.New("文本解码失败！")
+	}
+	return string(out), strings.ToLower(charset), nil
+}
+
+// Windows 代码页对应的编码，UTF-8 和不认识的代码页返回 nil
+func codePageEncoding(cp int) encoding.Encoding {
+	switch cp {
+	case 936, 10008: // 10008 为 Mac 简体中文
+		return simplifiedchinese.GBK
+	case 54936:
+		return simplifiedchinese.GB18030
+	case 950, 10002: // 10002 为 Mac 繁体中文
+		return traditionalchinese.Big5
+	case 1250:
+		return charmap.Windows1250
+	case 1251:
+		return charmap.Windows1251
+	case 1252:
+		return charmap.Windows1252
+	}
+	return nil
+}
@@ -0,0 +1,235 @@
+package office
+
+import (
+	"bytes"
+	"errors"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// epub 为 zip 包，META-INF/container.xml 指向 OPF 文件，OPF 的 manifest 列出包内文件，
+// spine 给出阅读顺序。每个章节是一个 xhtml 文件，按 html 提取；
+// 章节名取自目录，EPUB3 为 nav 文档，EPUB2 为 toc.ncx。
+
+// epub文件转结构化内容，按阅读顺序分章节
+func EpubToStructure(filePath string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return fileToWord(filePath, parseEpub)
+}
+
+// epub地址文件转结构化内容
+func EpubUrlToStructure(url string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return urlToWord(url, parseEpub)
+}
+
+type epubContainer struct {
+	Rootfiles []struct {
+		FullPath string `xml:"full-path,attr"`
+	} `xml:"rootfiles>rootfile"`
+}
+
+type epubItem struct {
+	Id         string `xml:"id,attr"`
+	Href       string `xml:"href,attr"`
+	MediaType  string `xml:"media-type,attr"`
+	Properties string `xml:"properties,attr"`
+}
+
+type epubOpf struct {
+	Items []epubItem `xml:"manifest>item"`
+	Spine struct {
+		Toc      string `xml:"toc,attr"`
+		Itemrefs []struct {
+			Idref string `xml:"idref,attr"`
+		} `xml:"itemref"`
+	} `xml:"spine"`
+}
+
+type epubNavPoint struct {
+	Label  string         `xml:"navLabel>text"`
+	Src    string         `xml:"content>src,attr"`
+	Points []epubNavPoint `xml:"navPoint"`
+}
+
+func parseEpub(filePath string) (*WordDocument, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	var container epubContainer
+	if err := pkg.decode("META-INF/container.xml", &container); err != nil || len(container.Rootfiles) == 0 {
+		return nil, errors.New("不是有效的epub文件！")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	var opf epubOpf
+	if err := pkg.decode(opfPath, &opf); err != nil {
+		return nil, errors.New("读取文件内容失败！")
+	}
+	dir := path.Dir(opfPath)
+	items := map[string]epubItem{}
+	for _, item := range opf.Items {
+		items[item.Id] = item
+	}
+	toc := epubToc(pkg, opf, items, dir)
+
+	doc := &WordDocument{}
+	for _, ref := range opf.Spine.Itemrefs {
+		item, ok := items[ref.Idref]
+		if !ok || (item.MediaType != "application/xhtml+xml" && item.MediaType != "text/html") || hasWord(item.Properties, "nav") {
+			continue
+		}
+		name := epubPath(dir, item.Href)
+		data, err := pkg.read(name)
+		if err != nil {
+			continue
+		}
+		blocks, err := htmlToBlocks(data)
+		if err != nil {
+			continue
+		}
+		// 章节名取自目录，目录中没有时取章节开头的标题；章节不以标题开头时补上章节名
+		title := toc[name]
+		startsWithHeading := len(blocks) > 0 && blocks[0].Paragraph != nil && blocks[0].Paragraph.HeadingLevel > 0
+		switch {
+		case title == "" && startsWithHeading:
+			title = blocks[0].Paragraph.Text
+		case title != "" && !startsWithHeading:
+			heading := &WordParagraph{Text: title, HeadingLevel: 1, Spans: []WordSpan{{Text: title}}}
+			blocks = append([]WordBlock{{Paragraph: heading}}, blocks...)
+		}
+		if len(blocks) == 0 {
+			continue
+		}
+		start := len(doc.Blocks)
+		doc.Blocks = append(doc.Blocks, blocks...)
+		doc.Chapters = append(doc.Chapters, WordChapter{Title: title, Href: name, Start: start, End: len(doc.Blocks)})
+	}
+	return doc, nil
+}
+
+// 包内路径，href 相对 OPF 所在目录并且经过 URL 编码，去掉 # 之后的锚点
+func epubPath(dir string, href string) string {
+	if i := strings.IndexByte(href, '#'); i >= 0 {
+		href = href[:i]
+	}
+	if s, err := url.PathUnescape(href); err == nil {
+		href = s
+	}
+	return strings.TrimPrefix(path.Join(dir, href), "./")
+}
+
+// 空格分隔的属性值中是否含有 word
+func hasWord(list string, word string) bool {
+	for _, w := range strings.Fields(list) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
+
+// 章节文件对应的章节名，同一文件有多个目录项时取第一个
+func epubToc(pkg *ooxmlPackage, opf epubOpf, items map[string]epubItem, dir string) map[string]string {
+	toc := map[string]string{}
+	add := func(base string, href string, title string) {
+		title = strings.Join(strings.Fields(title), " ")
+		if name := epubPath(base, href); title != "" && toc[name] == "" {
+			toc[name] = title
+		}
+	}
+	for _, item := range opf.Items {
+		if !hasWord(item.Properties, "nav") {
+			continue
+		}
+		name := epubPath(dir, item.Href)
+		data, err := pkg.read(name)
+		if err != nil {
+			continue
+		}
+		for _, a := range epubNavLinks(data) {
+			add(path.Dir(name), htmlAttr(a, "href"), htmlNodeText(a))
+		}
+		if len(toc) > 0 {
+			return toc
+		}
+	}
+	ncx, ok := items[opf.Spine.Toc]
+	if !ok {
+		return toc
+	}
+	name := epubPath(dir, ncx.Href)
+	var res struct {
+		Points []epubNavPoint `xml:"navMap>navPoint"`
+	}
+	if err := pkg.decode(name, &res); err != nil {
+		return toc
+	}
+	var walk func(points []epubNavPoint)
+	walk = func(points []epubNavPoint) {
+		for _, pt := range points {
+			add(path.Dir(name), pt.Src, pt.Label)
+			walk(pt.Points)
+		}
+	}
+	walk(res.Points)
+	return toc
+}
+
+// nav 文档中目录的链接，优先取 epub:type 为 toc 的 nav
+func epubNavLinks(data []byte) []*html.Node {
+	root, err := html.Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil
+	}
+	var navs []*html.Node
+	var find func(n *html.Node)
+	find = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.Nav {
+			navs = append(navs, n)
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			find(c)
+		}
+	}
+	find(root)
+	if len(navs) == 0 {
+		return nil
+	}
+	nav := navs[0]
+	for _, n := range navs {
+		if hasWord(htmlAttr(n, "epub:type"), "toc") {
+			nav = n
+			break
+		}
+	}
+	var links []*html.Node
+	var collect func(n *html.Node)
+	collect = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.DataAtom == atom.A && htmlAttr(n, "href") != "" {
+			links = append(links, n)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			collect(c)
+		}
+	}
+	collect(nav)
+	return links
+}
+
+// 元素内的全部文字
+func htmlNodeText(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var sb strings.Builder
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		sb.WriteString(htmlNodeText(c))
+	}
+	return sb.String()
+}
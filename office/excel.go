@@ -0,0 +1,256 @@
+package office
+
+import (
+	"errors"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// 单元格类型
+const (
+	CellTypeEmpty  = "empty"
+	CellTypeString = "string"
+	CellTypeNumber = "number"
+	CellTypeBool   = "bool"
+	CellTypeDate   = "date"
+	CellTypeError  = "error"
+)
+
+// excel工作簿
+type Workbook struct {
+	Sheets   []Sheet `json:"sheets"`
+	Date1904 bool    `json:"date_1904,omitempty"` // 日期以 1904-01-01 为起点（旧版 Mac 的 Excel）
+}
+
+// 工作表
+type Sheet struct {
+	Name    string   `json:"name"`
+	Hidden  bool     `json:"hidden,omitempty"`
+	Headers []string `json:"headers,omitempty"` // 表头，ExcelOptions.NoHeader 时为空
+	Rows    []Row    `json:"rows"`              // 数据行，不含表头和空行
+
+	date1904 bool // 日期以 1904-01-01 为起点（旧版 Mac 的 Excel）
+}
+
+// 数据行
+type Row struct {
+	Index int    `json:"index"` // 行号，从 1 开始
+	Cells []Cell `json:"cells"`
+}
+
+// 单元格
+type Cell struct {
+	Type    string     `json:"type"`              // 见 CellType* 常量
+	Text    string     `json:"text"`              // 按单元格格式显示的文本，日期统一为 2006-01-02 15:04:05 形式
+	Value   string     `json:"value,omitempty"`   // 原始值，公式单元格为缓存的计算结果
+	Formula string     `json:"formula,omitempty"` // 公式，不含开头的 =
+	NumFmt  string     `json:"num_fmt,omitempty"` // 数字格式
+	Number  float64    `json:"number,omitempty"`  // 数字和日期单元格的数值
+	Bool    bool       `json:"bool,omitempty"`    // 布尔单元格的值
+	Time    *time.Time `json:"time,omitempty"`    // 日期单元格的时间，按本地时区解释
+	Merged  bool       `json:"merged,omitempty"`  // 由合并区域填充而来
+}
+
+// excel读取选项
+type ExcelOptions struct {
+	NoHeader         bool // 没有表头，第一行也作为数据行
+	FillMergedDown   bool // 纵向合并的单元格向下填充起始单元格的值
+	FillMergedAcross bool // 横向合并的单元格向右填充起始单元格的值
+}
+
+// 按列号取单元格，从 1 开始，越界返回空单元格
+func (r Row) Cell(col int) Cell {
+	if col < 1 || col > len(r.Cells) {
+		return Cell{Type: CellTypeEmpty}
+	}
+	return r.Cells[col-1]
+}
+
+// 按名称查找工作表，找不到返回 nil
+func (w *Workbook) Sheet(name string) *Sheet {
+	for i := range w.Sheets {
+		if w.Sheets[i].Name == name {
+			return &w.Sheets[i]
+		}
+	}
+	return nil
+}
+
+// excel文件转工作簿，第一个非空行作为表头，csv/tsv文件按后缀识别，ods按文件内容识别
+func ExcelToWorkbook(filePath string) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	return ExcelToWorkbookWithOptions(filePath, ExcelOptions{})
+}
+
+// excel地址文件转工作簿
+func ExcelUrlToWorkbook(url string) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	return ExcelUrlToWorkbookWithOptions(url, ExcelOptions{})
+}
+
+// excel文件按选项转工作簿
+func ExcelToWorkbookWithOptions(filePath string, opts ExcelOptions) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	book, err = readWorkbook(filePath, filepath.Base(filePath), suffix, opts)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return book, suffix, size, nil
+}
+
+// excel地址文件按选项转工作簿
+func ExcelUrlToWorkbookWithOptions(url string, opts ExcelOptions) (book *Workbook, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return nil, "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	book, err = readWorkbook(filePath, path.Base(url), suffix, opts)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return book, suffix, size, nil
+}
+
+// csv/tsv 按后缀读取，工作表以文件名命名；xls、ods 按文件头识别；其余按 xlsx 读取
+func readWorkbook(filePath string, name string, suffix string, opts ExcelOptions) (*Workbook, error) {
+	if isCsvSuffix(suffix) {
+		return parseCsv(filePath, csvSheetName(name), suffix, CsvOptions{NoHeader: opts.NoHeader})
+	}
+	if isOleFile(filePath) {
+		return parseLegacyExcel(filePath, opts)
+	}
+	if odfFileFormat(filePath) == FormatOds {
+		return parseOds(filePath, opts)
+	}
+	return parseExcel(filePath, opts)
+}
+
+func parseExcel(filePath string, opts ExcelOptions) (*Workbook, error) {
+	xlFile, err := xlsx.OpenFile(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+
+	book := &Workbook{Date1904: xlFile.Date1904}
+	for _, sheet := range xlFile.Sheets {
+		grid := make([][]Cell, len(sheet.Rows))
+		for k, row := range sheet.Rows {
+			if row == nil {
+				continue
+			}
+			grid[k] = make([]Cell, 0, len(row.Cells))
+			for _, cell := range row.Cells {
+				grid[k] = append(grid[k], convertCell(cell, xlFile.Date1904))
+			}
+		}
+		if opts.FillMergedDown || opts.FillMergedAcross {
+			fillMerged(grid, sheetMerges(sheet), opts)
+		}
+		book.Sheets = append(book.Sheets, gridSheet(sheet.Name, sheet.Hidden, grid, opts, xlFile.Date1904))
+	}
+	return book, nil
+}
+
+func isEmptyRow(cells []Cell) bool {
+	for _, c := range cells {
+		if c.Type != CellTypeEmpty && strings.TrimSpace(c.Text) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// 整张表的单元格转为工作表，第一个非空行作为表头，空行跳过
+func gridSheet(name string, hidden bool, grid [][]Cell, opts ExcelOptions, date1904 bool) Sheet {
+	s := Sheet{Name: name, Hidden: hidden, Rows: []Row{}, date1904: date1904}
+	headerDone := opts.NoHeader
+	for k, cells := range grid {
+		if isEmptyRow(cells) {
+			continue
+		}
+		if !headerDone {
+			for _, c := range cells {
+				s.Headers = append(s.Headers, strings.TrimSpace(c.Text))
+			}
+			headerDone = true
+			continue
+		}
+		s.Rows = append(s.Rows, Row{Index: k + 1, Cells: cells})
+	}
+	return s
+}
+
+// 合并区域，行列从 0 开始
+type mergeRange struct {
+	row, col         int
+	lastRow, lastCol int
+}
+
+func sheetMerges(sheet *xlsx.Sheet) []mergeRange {
+	var list []mergeRange
+	for k, row := range sheet.Rows {
+		if row == nil {
+			continue
+		}
+		for j, cell := range row.Cells {
+			if cell != nil && (cell.HMerge > 0 || cell.VMerge > 0) {
+				list = append(list, mergeRange{row: k, col: j, lastRow: k + cell.VMerge, lastCol: j + cell.HMerge})
+			}
+		}
+	}
+	return list
+}
+
+// 合并区域的起始单元格复制到区域内其他单元格
+func fillMerged(grid [][]Cell, merges []mergeRange, opts ExcelOptions) {
+	for _, m := range merges {
+		if m.row >= len(grid) || m.col >= len(grid[m.row]) {
+			continue
+		}
+		origin := grid[m.row][m.col]
+		origin.Merged = true
+		for k := m.row; k <= m.lastRow && k < len(grid); k++ {
+			for j := m.col; j <= m.lastCol; j++ {
+				if k == m.row && j == m.col {
+					continue
+				}
+				if (k > m.row && !opts.FillMergedDown) || (j > m.col && !opts.FillMergedAcross) {
+					continue
+				}
+				for len(grid[k]) <= j {
+					grid[k] = append(grid[k], Cell{Type: CellTypeEmpty})
+				}
+				grid[k][j] = origin
+			}
+		}
+	}
+}
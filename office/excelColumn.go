@@ -0,0 +1,75 @@
+package office
+
+import (
+	"errors"
+	"strings"
+)
+
+// 列映射，先按表头匹配，表头都未匹配时按列号取值
+type Column struct {
+	Field   string   // 结果中的字段名
+	Headers []string // 表头名，任一相同即可，忽略首尾空白和大小写
+	Index   int      // 列号，从 1 开始，0 表示不按列号取值
+}
+
+// 按列映射取出的一行数据
+type Record struct {
+	Row   int             `json:"row"` // 行号，从 1 开始
+	Cells map[string]Cell `json:"cells"`
+}
+
+// 字段的显示文本，字段不存在时返回空字符串
+func (r Record) Get(field string) string {
+	return strings.TrimSpace(r.Cells[field].Text)
+}
+
+// 列在表中的列号，从 1 开始，未找到返回 0
+func (s *Sheet) ColumnIndex(c Column) int {
+	for _, name := range c.Headers {
+		for i, h := range s.Headers {
+			if strings.EqualFold(strings.TrimSpace(name), h) {
+				return i + 1
+			}
+		}
+	}
+	return c.Index
+}
+
+// 按列映射取出所有数据行，映射的列全部为空的行跳过
+func (s *Sheet) Records(columns []Column) ([]Record, error) {
+	index := make([]int, len(columns))
+	var missing []string
+	for i, c := range columns {
+		index[i] = s.ColumnIndex(c)
+		if index[i] == 0 {
+			missing = append(missing, columnName(c))
+		}
+	}
+	if len(missing) > 0 {
+		return nil, errors.New("工作表 " + s.Name + " 缺少列：" + strings.Join(missing, "、") + "！")
+	}
+
+	var records []Record
+	for _, row := range s.Rows {
+		rec := Record{Row: row.Index, Cells: make(map[string]Cell, len(columns))}
+		empty := true
+		for i, c := range columns {
+			cell := row.Cell(index[i])
+			if cell.Type != CellTypeEmpty {
+				empty = false
+			}
+			rec.Cells[c.Field] = cell
+		}
+		if !empty {
+			records = append(records, rec)
+		}
+	}
+	return records, nil
+}
+
+func columnName(c Column) string {
+	if len(c.Headers) > 0 {
+		return c.Headers[0]
+	}
+	return c.Field
+}
@@ -0,0 +1,391 @@
+package office
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 回调中返回该错误可提前结束遍历，EachRow 不会把它当作错误返回
+var ErrStopIteration = errors.New("stop iteration")
+
+const relWorksheet = "/worksheet"
+
+// 内置数字格式，含中文版 Excel 的日期格式（27-36、50-58）
+var builtinNumFmts = map[int]string{
+	0: "general", 1: "0", 2: "0.00", 3: "#,##0", 4: "#,##0.00",
+	9: "0%", 10: "0.00%", 11: "0.00e+00", 12: "# ?/?", 13: "# ??/??",
+	14: "yyyy/m/d", 15: "d-mmm-yy", 16: "d-mmm", 17: "mmm-yy",
+	18: "h:mm am/pm", 19: "h:mm:ss am/pm", 20: "h:mm", 21: "h:mm:ss", 22: "yyyy/m/d h:mm",
+	27: `yyyy"年"m"月"`, 28: `m"月"d"日"`, 29: `m"月"d"日"`, 30: "m-d-yy", 31: `yyyy"年"m"月"d"日"`,
+	32: `h"时"mm"分"`, 33: `h"时"mm"分"ss"秒"`, 34: `上午/下午h"时"mm"分"`, 35: `上午/下午h"时"mm"分"ss"秒"`, 36: `yyyy"年"m"月"`,
+	37: "#,##0 ;(#,##0)", 38: "#,##0 ;[red](#,##0)", 39: "#,##0.00;(#,##0.00)", 40: "#,##0.00;[red](#,##0.00)",
+	45: "mm:ss", 46: "[h]:mm:ss", 47: "mmss.0", 48: "##0.0e+0", 49: "@",
+	50: `yyyy"年"m"月"`, 51: `m"月"d"日"`, 52: `yyyy"年"m"月"`, 53: `m"月"d"日"`, 54: `m"月"d"日"`,
+	55: `上午/下午h"时"mm"分"`, 56: `上午/下午h"时"mm"分"ss"秒"`, 57: `yyyy"年"m"月"`, 58: `m"月"d"日"`,
+}
+
+// 流式读取 xlsx，逐行解析工作表 xml，内存占用与行数无关（共享字符串表除外）
+//
+//	s, err := office.OpenExcelStream(path)
+//	defer s.Close()
+//	err = s.EachRow("", func(row office.Row) error { ... })
+type ExcelStream struct {
+	pkg      *ooxmlPackage
+	sheets   []streamSheet
+	strings  []string // 共享字符串
+	numFmts  []string // 样式编号 -> 数字格式
+	date1904 bool
+}
+
+type streamSheet struct {
+	name string
+	part string
+}
+
+func OpenExcelStream(filePath string) (*ExcelStream, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	s := &ExcelStream{pkg: pkg}
+	if err := s.load(); err != nil {
+		pkg.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *ExcelStream) Close() error {
+	return s.pkg.Close()
+}
+
+// 所有工作表名，按工作簿中的顺序
+func (s *ExcelStream) SheetNames() []string {
+	names := make([]string, 0, len(s.sheets))
+	for _, sh := range s.sheets {
+		names = append(names, sh.name)
+	}
+	return names
+}
+
+// 依次回调工作表中的每个非空行，sheetName 为空时取第一个工作表
+// 回调返回 ErrStopIteration 时提前结束并返回 nil，返回其他错误时结束并返回该错误
+func (s *ExcelStream) EachRow(sheetName string, fn func(row Row) error) error {
+	it, err := s.Rows(sheetName)
+	if err != nil {
+		return err
+	}
+	defer it.Close()
+	for it.Next() {
+		if err := fn(it.Row()); err != nil {
+			if err == ErrStopIteration {
+				return nil
+			}
+			return err
+		}
+	}
+	return it.Err()
+}
+
+// 行迭代器，用完须调用 Close
+//
+//	it, err := s.Rows("")
+//	defer it.Close()
+//	for it.Next() { row := it.Row() }
+//	err = it.Err()
+func (s *ExcelStream) Rows(sheetName string) (*RowIterator, error) {
+	if len(s.sheets) == 0 {
+		return nil, errors.New("工作簿中没有工作表！")
+	}
+	sheet := s.sheets[0]
+	if sheetName != "" {
+		found := false
+		for _, sh := range s.sheets {
+			if sh.name == sheetName {
+				sheet, found = sh, true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.New("工作表 " + sheetName + " 不存在！")
+		}
+	}
+	rc, err := s.pkg.open(sheet.part)
+	if err != nil {
+		return nil, errors.New("读取工作表 " + sheet.name + " 失败！")
+	}
+	return &RowIterator{stream: s, rc: rc, d: xml.NewDecoder(rc)}, nil
+}
+
+// 工作表行迭代器
+type RowIterator struct {
+	stream *ExcelStream
+	rc     io.ReadCloser
+	d      *xml.Decoder
+	row    Row
+	err    error
+	done   bool
+}
+
+type xlsxRowXml struct {
+	R int           `xml:"r,attr"`
+	C []xlsxCellXml `xml:"c"`
+}
+
+type xlsxCellXml struct {
+	R  string `xml:"r,attr"`
+	T  string `xml:"t,attr"`
+	S  int    `xml:"s,attr"`
+	F  string `xml:"f"`
+	V  string `xml:"v"`
+	Is *struct {
+		T string `xml:"t"`
+		R []struct {
+			T string `xml:"t"`
+		} `xml:"r"`
+	} `xml:"is"`
+}
+
+// 读取下一个非空行，没有更多行或出错时返回 false
+func (it *RowIterator) Next() bool {
+	if it.done {
+		return false
+	}
+	lastRow := it.row.Index
+	for {
+		tok, err := it.d.Token()
+		if err != nil {
+			if err != io.EOF {
+				it.err = err
+			}
+			it.done = true
+			return false
+		}
+		// sheetData 之后的合并区域、数据验证等不需要读取
+		if end, ok := tok.(xml.EndElement); ok && end.Name.Local == "sheetData" {
+			it.done = true
+			return false
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var x xlsxRowXml
+		if err := it.d.DecodeElement(&x, &start); err != nil {
+			it.err = err
+			it.done = true
+			return false
+		}
+		// r 属性可省略，省略时为上一行的下一行
+		if x.R == 0 {
+			x.R = lastRow + 1
+		}
+		lastRow = x.R
+		it.row = it.stream.convertRow(x)
+		if !isEmptyRow(it.row.Cells) {
+			return true
+		}
+	}
+}
+
+// 当前行
+func (it *RowIterator) Row() Row {
+	return it.row
+}
+
+// 遍历中的错误
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+func (it *RowIterator) Close() error {
+	it.done = true
+	return it.rc.Close()
+}
+
+func (s *ExcelStream) convertRow(x xlsxRowXml) Row {
+	row := Row{Index: x.R}
+	next := 0
+	for _, c := range x.C {
+		col := next
+		// r 属性可省略，省略时为上一个单元格的下一列
+		if c.R != "" {
+			if i, _, err := xlsx.GetCoordsFromCellIDString(c.R); err == nil {
+				col = i
+			}
+		}
+		for len(row.Cells) < col {
+			row.Cells = append(row.Cells, Cell{Type: CellTypeEmpty})
+		}
+		if col < len(row.Cells) {
+			row.Cells[col] = s.convertCell(c)
+		} else {
+			row.Cells = append(row.Cells, s.convertCell(c))
+		}
+		next = col + 1
+	}
+	return row
+}
+
+func (s *ExcelStream) convertCell(x xlsxCellXml) Cell {
+	numFmt := ""
+	if x.S >= 0 && x.S < len(s.numFmts) {
+		numFmt = s.numFmts[x.S]
+	}
+	// 没有值的单元格（只有样式或公式未缓存结果）视为空
+	if x.V == "" && x.T != "inlineStr" {
+		return Cell{Type: CellTypeEmpty, Formula: x.F, NumFmt: numFmt}
+	}
+	var c Cell
+	switch x.T {
+	case "s":
+		i, err := strconv.Atoi(strings.TrimSpace(x.V))
+		if err != nil || i < 0 || i >= len(s.strings) {
+			return Cell{Type: CellTypeError, Text: "#REF!", Value: x.V}
+		}
+		c = stringCell(s.strings[i])
+	case "inlineStr":
+		text := ""
+		if x.Is != nil {
+			text = x.Is.T
+			for _, r := range x.Is.R {
+				text += r.T
+			}
+		}
+		c = stringCell(text)
+	case "str":
+		c = stringCell(x.V)
+	case "b":
+		c = boolCell(strings.TrimSpace(x.V) == "1")
+	case "e":
+		return Cell{Type: CellTypeError, Text: x.V, Value: x.V, Formula: x.F}
+	case "d":
+		c = Cell{Type: CellTypeString, Text: x.V, Value: x.V, NumFmt: numFmt}
+		if t := parseIsoDate(x.V); t != nil {
+			c.Type, c.Time, c.Text = CellTypeDate, t, formatDate(*t, numFmt)
+		}
+	default:
+		c = numberCell(x.V, numFmt, s.date1904)
+	}
+	c.Formula = x.F
+	return c
+}
+
+// 读取工作簿结构、共享字符串和样式
+func (s *ExcelStream) load() error {
+	main := s.pkg.mainPart("xl/workbook.xml")
+	sheets, date1904, err := workbookSheets(s.pkg, main)
+	if err != nil {
+		return err
+	}
+	s.sheets, s.date1904 = sheets, date1904
+
+	if part := s.pkg.relTarget(main, "/sharedStrings"); part != "" {
+		if err := s.loadSharedStrings(part); err != nil {
+			return errors.New("读取共享字符串失败！")
+		}
+	}
+	if part := s.pkg.relTarget(main, "/styles"); part != "" {
+		s.loadStyles(part)
+	}
+	return nil
+}
+
+// 工作簿中按顺序排列的工作表及其部件，以及是否使用 1904 日期系统
+func workbookSheets(pkg *ooxmlPackage, main string) ([]streamSheet, bool, error) {
+	var wb struct {
+		Pr struct {
+			Date1904 string `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			Id   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := pkg.decode(main, &wb); err != nil {
+		return nil, false, errors.New("读取工作簿失败！")
+	}
+
+	targets := map[string]string{}
+	for _, r := range pkg.rels(main) {
+		if strings.HasSuffix(r.Type, relWorksheet) {
+			targets[r.Id] = r.Target
+		}
+	}
+	var sheets []streamSheet
+	for _, sh := range wb.Sheets {
+		// 图表工作表等没有对应的 worksheet 关系
+		if part, ok := targets[sh.Id]; ok {
+			sheets = append(sheets, streamSheet{name: sh.Name, part: part})
+		}
+	}
+	return sheets, xmlBool(wb.Pr.Date1904), nil
+}
+
+// 共享字符串逐项解码，富文本各段拼接，忽略注音
+func (s *ExcelStream) loadSharedStrings(part string) error {
+	rc, err := s.pkg.open(part)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok || start.Name.Local != "si" {
+			continue
+		}
+		var si struct {
+			T string `xml:"t"`
+			R []struct {
+				T string `xml:"t"`
+			} `xml:"r"`
+		}
+		if err := d.DecodeElement(&si, &start); err != nil {
+			return err
+		}
+		text := si.T
+		for _, r := range si.R {
+			text += r.T
+		}
+		s.strings = append(s.strings, text)
+	}
+}
+
+func (s *ExcelStream) loadStyles(part string) {
+	var styles struct {
+		NumFmts []struct {
+			Id   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		Xfs []struct {
+			NumFmtId int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if s.pkg.decode(part, &styles) != nil {
+		return
+	}
+	custom := map[int]string{}
+	for _, f := range styles.NumFmts {
+		custom[f.Id] = f.Code
+	}
+	for _, xf := range styles.Xfs {
+		code, ok := custom[xf.NumFmtId]
+		if !ok {
+			code = builtinNumFmts[xf.NumFmtId]
+		}
+		s.numFmts = append(s.numFmts, code)
+	}
+}
@@ -0,0 +1,406 @@
+package office
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+
+	"github.com/bangongyi/toolkits/xerr"
+)
+
+// 单元格导入错误
+type CellError struct {
+	Sheet  string `json:"sheet"`
+	Row    int    `json:"row"`    // 行号，从 1 开始，0 表示整列的问题（如缺少列）
+	Column string `json:"column"` // 表头名，没有表头时为列字母
+	Reason string `json:"reason"`
+}
+
+func (e CellError) Error() string {
+	if e.Row == 0 {
+		return fmt.Sprintf("工作表「%s」「%s」列：%s", e.Sheet, e.Column, e.Reason)
+	}
+	return fmt.Sprintf("工作表「%s」第%d行「%s」列：%s", e.Sheet, e.Row, e.Column, e.Reason)
+}
+
+// 导入错误报告，包含所有出错的单元格
+type ImportErrors []CellError
+
+func (e ImportErrors) Error() string {
+	list := make([]string, 0, len(e))
+	for _, c := range e {
+		list = append(list, c.Error())
+	}
+	return strings.Join(list, "；")
+}
+
+// 转为参数错误，便于直接返回给前端
+func (e ImportErrors) ParamErr() *xerr.CodeError {
+	return xerr.NewParamErr(e.Error())
+}
+
+// 字段映射规则，来自 xlsx 标签，如 `xlsx:"header=姓名|名字,required,max=20"`
+//
+//	header=名称   表头名，多个用 | 分隔，默认为字段名
+//	index=N       表头未匹配时按列号取值，从 1 开始
+//	required      不能为空
+//	min=N,max=N   数值的取值范围，字符串为长度范围
+//	oneof=a|b     只能取列出的值
+//	pattern=正则  需匹配的正则，不能包含逗号
+//	format=布局   日期的解析格式，Go 时间布局，如 2006-01-02
+//	default=值    单元格为空时使用的值
+//	width=N       导出时的列宽（字符数）
+//	numfmt=格式   导出时的 Excel 数字格式，如 yyyy-mm-dd、0.00
+//	hint=说明     导入模板中选中单元格时显示的提示，不能包含英文逗号
+//	-             忽略该字段
+type fieldRule struct {
+	index    []int // 字段在结构体中的位置
+	column   Column
+	required bool
+	min, max *float64
+	oneof    []string
+	pattern  *regexp.Regexp
+	format   string
+	def      string
+	width    float64
+	numfmt   string
+	hint     string
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+
+// 常见日期格式，format 未指定时依次尝试
+var dateLayouts = []string{
+	"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02",
+	"2006/01/02 15:04:05", "2006/01/02 15:04", "2006/01/02",
+	"2006-1-2", "2006/1/2", "2006.1.2", "2006年1月2日", "20060102",
+	time.RFC3339,
+}
+
+// 将工作簿中的工作表解析到 out，out 必须是结构体切片的指针，sheetName 为空时取第一个工作表
+func (w *Workbook) Unmarshal(sheetName string, out interface{}) error {
+	if len(w.Sheets) == 0 {
+		return errors.New("工作簿中没有工作表！")
+	}
+	sheet := &w.Sheets[0]
+	if sheetName != "" {
+		if sheet = w.Sheet(sheetName); sheet == nil {
+			return errors.New("工作表 " + sheetName + " 不存在！")
+		}
+	}
+	return sheet.Unmarshal(out)
+}
+
+// 将数据行解析到 out，out 必须是结构体切片（或结构体指针切片）的指针
+// 单元格转换或校验失败时返回 ImportErrors，已解析的行仍然写入 out
+func (s *Sheet) Unmarshal(out interface{}) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("out 必须是切片的指针！")
+	}
+	slice := rv.Elem()
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+	if elemType.Kind() != reflect.Struct {
+		return errors.New("out 的元素必须是结构体！")
+	}
+
+	rules, err := parseFieldRules(elemType)
+	if err != nil {
+		return err
+	}
+
+	var report ImportErrors
+	cols := make([]int, len(rules))
+	for i, rule := range rules {
+		cols[i] = s.ColumnIndex(rule.column)
+		if cols[i] == 0 && rule.required {
+			report = append(report, CellError{Sheet: s.Name, Column: columnName(rule.column), Reason: "缺少该列"})
+		}
+	}
+	if len(report) > 0 {
+		return report
+	}
+
+	for _, row := range s.Rows {
+		elem := reflect.New(elemType).Elem()
+		for i, rule := range rules {
+			if cols[i] == 0 {
+				continue
+			}
+			if reason := s.setField(elem.FieldByIndex(rule.index), row.Cell(cols[i]), rule); reason != "" {
+				report = append(report, CellError{Sheet: s.Name, Row: row.Index, Column: s.columnTitle(cols[i]), Reason: reason})
+			}
+		}
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem.Addr()))
+		} else {
+			slice.Set(reflect.Append(slice, elem))
+		}
+	}
+	if len(report) > 0 {
+		return report
+	}
+	return nil
+}
+
+// 列标题，优先表头，其次列字母
+func (s *Sheet) columnTitle(col int) string {
+	if col <= len(s.Headers) && s.Headers[col-1] != "" {
+		return s.Headers[col-1]
+	}
+	return xlsx.ColIndexToLetters(col - 1)
+}
+
+func parseFieldRules(t reflect.Type) ([]fieldRule, error) {
+	var rules []fieldRule
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("xlsx")
+		if tag == "-" {
+			continue
+		}
+		rule := fieldRule{index: f.Index, column: Column{Field: f.Name, Headers: []string{f.Name}}}
+		for _, opt := range strings.Split(tag, ",") {
+			opt = strings.TrimSpace(opt)
+			if opt == "" {
+				continue
+			}
+			key, value := opt, ""
+			if k := strings.Index(opt, "="); k >= 0 {
+				key, value = opt[:k], opt[k+1:]
+			}
+			switch key {
+			case "header":
+				rule.column.Headers = strings.Split(value, "|")
+			case "index":
+				n, err := strconv.Atoi(value)
+				if err != nil || n < 1 {
+					return nil, errors.New("字段 " + f.Name + " 的 index 无效！")
+				}
+				rule.column.Index = n
+			case "required":
+				rule.required = true
+			case "min", "max":
+				n, err := strconv.ParseFloat(value, 64)
+				if err != nil {
+					return nil, errors.New("字段 " + f.Name + " 的 " + key + " 无效！")
+				}
+				if key == "min" {
+					rule.min = &n
+				} else {
+					rule.max = &n
+				}
+			case "oneof":
+				rule.oneof = strings.Split(value, "|")
+			case "pattern":
+				re, err := regexp.Compile(value)
+				if err != nil {
+					return nil, errors.New("字段 " + f.Name + " 的 pattern 无效！")
+				}
+				rule.pattern = re
+			case "format":
+				rule.format = value
+			case "default":
+				rule.def = value
+			case "width":
+				n, err := strconv.ParseFloat(value, 64)
+				if err != nil || n <= 0 {
+					return nil, errors.New("字段 " + f.Name + " 的 width 无效！")
+				}
+				rule.width = n
+			case "numfmt":
+				rule.numfmt = value
+			case "hint":
+				rule.hint = value
+			default:
+				return nil, errors.New("字段 " + f.Name + " 的标签 " + key + " 不支持！")
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// 转换并校验单元格，失败时返回原因
+func (s *Sheet) setField(field reflect.Value, cell Cell, rule fieldRule) string {
+	text := strings.TrimSpace(cell.Text)
+	if cell.Type == CellTypeEmpty || text == "" {
+		if rule.def == "" {
+			if rule.required {
+				return "不能为空"
+			}
+			return ""
+		}
+		cell = Cell{Type: CellTypeString, Text: rule.def, Value: rule.def}
+		text = rule.def
+	}
+	if cell.Type == CellTypeError {
+		return "单元格错误 " + text
+	}
+
+	if len(rule.oneof) > 0 && !containsString(rule.oneof, text) {
+		return "只能是 " + strings.Join(rule.oneof, "、") + " 之一"
+	}
+	if rule.pattern != nil && !rule.pattern.MatchString(text) {
+		return "格式不正确"
+	}
+
+	// 指针字段有值时分配
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	if field.CanAddr() && field.Addr().Type().Implements(textUnmarshalerType) && field.Type() != timeType {
+		if err := field.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(text)); err != nil {
+			return "格式不正确"
+		}
+		return ""
+	}
+
+	switch {
+	case field.Type() == timeType:
+		t, ok := s.parseTime(cell, rule.format)
+		if !ok {
+			return "不是有效的日期"
+		}
+		field.Set(reflect.ValueOf(t))
+		return ""
+	case field.Kind() == reflect.String:
+		if reason := checkRange(float64(len([]rune(text))), rule, "长度"); reason != "" {
+			return reason
+		}
+		field.SetString(text)
+	case field.Kind() == reflect.Bool:
+		if cell.Type == CellTypeBool {
+			field.SetBool(cell.Bool)
+			return ""
+		}
+		b, ok := parseBool(text)
+		if !ok {
+			return "不是有效的是/否"
+		}
+		field.SetBool(b)
+	case field.Kind() >= reflect.Int && field.Kind() <= reflect.Int64:
+		n, ok := cellNumber(cell)
+		if !ok || n != float64(int64(n)) {
+			return "不是整数"
+		}
+		if field.OverflowInt(int64(n)) {
+			return "数值超出范围"
+		}
+		if reason := checkRange(n, rule, "数值"); reason != "" {
+			return reason
+		}
+		field.SetInt(int64(n))
+	case field.Kind() >= reflect.Uint && field.Kind() <= reflect.Uint64:
+		n, ok := cellNumber(cell)
+		if !ok || n < 0 || n != float64(uint64(n)) {
+			return "不是非负整数"
+		}
+		if field.OverflowUint(uint64(n)) {
+			return "数值超出范围"
+		}
+		if reason := checkRange(n, rule, "数值"); reason != "" {
+			return reason
+		}
+		field.SetUint(uint64(n))
+	case field.Kind() == reflect.Float32 || field.Kind() == reflect.Float64:
+		n, ok := cellNumber(cell)
+		if !ok {
+			return "不是数字"
+		}
+		if reason := checkRange(n, rule, "数值"); reason != "" {
+			return reason
+		}
+		field.SetFloat(n)
+	default:
+		return "不支持的字段类型 " + field.Type().String()
+	}
+	return ""
+}
+
+func checkRange(n float64, rule fieldRule, what string) string {
+	if rule.min != nil && n < *rule.min {
+		return what + "不能小于 " + strconv.FormatFloat(*rule.min, 'f', -1, 64)
+	}
+	if rule.max != nil && n > *rule.max {
+		return what + "不能大于 " + strconv.FormatFloat(*rule.max, 'f', -1, 64)
+	}
+	return ""
+}
+
+// 单元格的数值，数字单元格取原始值，文本单元格去掉千分位和百分号后解析
+func cellNumber(cell Cell) (float64, bool) {
+	if cell.Type == CellTypeNumber {
+		return cell.Number, true
+	}
+	text := strings.ReplaceAll(strings.TrimSpace(cell.Text), ",", "")
+	percent := strings.HasSuffix(text, "%")
+	n, err := strconv.ParseFloat(strings.TrimSuffix(text, "%"), 64)
+	if percent {
+		n /= 100
+	}
+	return n, err == nil
+}
+
+func (s *Sheet) parseTime(cell Cell, format string) (time.Time, bool) {
+	if cell.Time != nil {
+		return *cell.Time, true
+	}
+	// 常规格式的数字按日期序号解释
+	if cell.Type == CellTypeNumber {
+		if n, err := strconv.ParseFloat(cell.Value, 64); err == nil {
+			// Excel 日期不带时区，按本地时间解释，和文本日期保持一致
+			t := xlsx.TimeFromExcelTime(n, s.date1904)
+			return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local), true
+		}
+	}
+	text := strings.TrimSpace(cell.Text)
+	layouts := dateLayouts
+	if format != "" {
+		layouts = []string{format}
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, text, time.Local); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func parseBool(text string) (bool, bool) {
+	switch strings.ToLower(text) {
+	case "1", "true", "t", "yes", "y", "是", "对", "√", "有":
+		return true, true
+	case "0", "false", "f", "no", "n", "否", "错", "×", "无":
+		return false, true
+	}
+	return false, false
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
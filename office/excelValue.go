@@ -0,0 +1,195 @@
+package office
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 单元格的类型化值：日期为 time.Time，数字为 float64，布尔为 bool，其余为 string，空单元格为 nil
+func (c Cell) Typed() interface{} {
+	switch c.Type {
+	case CellTypeEmpty:
+		return nil
+	case CellTypeNumber:
+		return c.Number
+	case CellTypeBool:
+		return c.Bool
+	case CellTypeDate:
+		if c.Time != nil {
+			return *c.Time
+		}
+	}
+	return c.Text
+}
+
+func convertCell(cell *xlsx.Cell, date1904 bool) Cell {
+	if cell == nil {
+		return Cell{Type: CellTypeEmpty}
+	}
+	// 公式单元格的 Value 是缓存的计算结果，没有缓存（未经 Excel 计算保存）时视为空
+	c := Cell{Value: cell.Value, Formula: cell.Formula(), NumFmt: cell.NumFmt}
+	if cell.Value == "" {
+		c.Type = CellTypeEmpty
+		return c
+	}
+
+	switch cell.Type() {
+	case xlsx.CellTypeNumeric:
+		n, err := strconv.ParseFloat(cell.Value, 64)
+		if err != nil {
+			c.Type, c.Text = CellTypeString, cell.Value
+			return c
+		}
+		c.Number = n
+		if isDateFormat(cell.NumFmt) {
+			t := excelToLocal(xlsx.TimeFromExcelTime(n, date1904))
+			c.Type, c.Time, c.Text = CellTypeDate, &t, formatDate(t, cell.NumFmt)
+			return c
+		}
+		c.Type = CellTypeNumber
+		text, err := cell.FormattedValue()
+		if err != nil || text == "" {
+			// 不支持的数字格式，使用不带科学计数法的原始数值
+			text = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		c.Text = text
+	case xlsx.CellTypeBool:
+		c.Type, c.Bool = CellTypeBool, cell.Value == "1"
+		c.Text = strings.ToUpper(strconv.FormatBool(c.Bool))
+	case xlsx.CellTypeError:
+		c.Type, c.Text = CellTypeError, cell.Value
+	case xlsx.CellTypeDate:
+		// ISO 8601 格式的日期单元格（t="d"）
+		c.Type, c.Text = CellTypeString, cell.Value
+		if t := parseIsoDate(cell.Value); t != nil {
+			c.Type, c.Time, c.Text = CellTypeDate, t, formatDate(*t, "")
+		}
+	default:
+		c.Type, c.Text = CellTypeString, cell.Value
+	}
+	return c
+}
+
+// 按数字格式转换数值单元格，value 为原始数值文本
+func numberCell(value string, numFmt string, date1904 bool) Cell {
+	cell := &xlsx.Cell{}
+	cell.SetFloatWithFormat(0, numFmt)
+	cell.Value = value
+	return convertCell(cell, date1904)
+}
+
+func boolCell(b bool) Cell {
+	cell := &xlsx.Cell{}
+	cell.SetBool(b)
+	return convertCell(cell, false)
+}
+
+func stringCell(text string) Cell {
+	cell := &xlsx.Cell{}
+	cell.SetString(text)
+	return convertCell(cell, false)
+}
+
+// ISO 8601 格式的日期，无法解析时返回 nil
+func parseIsoDate(v string) *time.Time {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// Excel 日期没有时区，按本地时间解释
+func excelToLocal(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.Local).Round(time.Millisecond)
+}
+
+// 日期统一输出为 2006-01-02 / 15:04:05 / 2006-01-02 15:04:05，按格式中是否含日期、时间部分决定
+func formatDate(t time.Time, numFmt string) string {
+	hasDate, hasTime := dateFormatParts(numFmt)
+	if !hasDate && !hasTime {
+		hasDate = true
+		hasTime = t.Hour() != 0 || t.Minute() != 0 || t.Second() != 0
+	}
+	switch {
+	case hasDate && hasTime:
+		return t.Format("2006-01-02 15:04:05")
+	case hasTime:
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02")
+}
+
+// 是否为日期或时间格式
+func isDateFormat(numFmt string) bool {
+	hasDate, hasTime := dateFormatParts(numFmt)
+	return hasDate || hasTime
+}
+
+// 分析数字格式中的日期和时间部分，忽略引号内文字、转义字符和颜色等方括号标记
+func dateFormatParts(numFmt string) (hasDate bool, hasTime bool) {
+	code := strings.ToLower(numFmt)
+	if code == "" || code == "general" || code == "@" {
+		return false, false
+	}
+	// 多段格式只看第一段
+	if i := strings.Index(code, ";"); i >= 0 {
+		code = code[:i]
+	}
+	var sb strings.Builder
+	for i := 0; i < len(code); i++ {
+		switch code[i] {
+		case '"':
+			if j := strings.IndexByte(code[i+1:], '"'); j >= 0 {
+				i += j + 1
+			} else {
+				i = len(code)
+			}
+		case '\\', '_', '*':
+			i++
+		case '[':
+			j := strings.IndexByte(code[i:], ']')
+			if j < 0 {
+				i = len(code)
+				continue
+			}
+			// [h]、[mm]、[ss] 表示累计时长
+			inner := code[i+1 : i+j]
+			if inner != "" && strings.Trim(inner, "hms") == "" {
+				sb.WriteString(inner)
+			}
+			i += j
+		case '.':
+			// ss.000 中的 0 是秒的小数位，不是数字占位符
+			if strings.HasSuffix(sb.String(), "s") {
+				for i+1 < len(code) && code[i+1] == '0' {
+					i++
+				}
+			}
+			sb.WriteByte('.')
+		default:
+			// 段中的 General 是常规数字格式，其中的字母不是日期标记
+			if strings.HasPrefix(code[i:], "general") {
+				i += len("general") - 1
+				continue
+			}
+			sb.WriteByte(code[i])
+		}
+	}
+	plain := sb.String()
+	hasDate = strings.ContainsAny(plain, "yde") || strings.Contains(plain, "g")
+	hasTime = strings.ContainsAny(plain, "hs")
+	// m 单独出现时表示月份，和 h、s 一起时表示分钟
+	if strings.Contains(plain, "m") && !hasTime {
+		hasDate = true
+	}
+	// 科学计数法中的 e 不是日期
+	if hasDate && strings.ContainsAny(plain, "0#?") {
+		hasDate = false
+	}
+	return hasDate, hasTime
+}
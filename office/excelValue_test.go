@@ -0,0 +1,30 @@
+package office
+
+import "testing"
+
+func TestDateFormatParts(t *testing.T) {
+	cases := []struct {
+		numFmt           string
+		hasDate, hasTime bool
+	}{
+		{"General", false, false},
+		{"General;[Red]-General", false, false},
+		{"GENERAL;-general", false, false},
+		{"0.00E+00", false, false},
+		{`#,##0.00 "元"`, false, false},
+		{"yyyy-mm-dd", true, false},
+		{"[$-F800]dddd\\,\\ mmmm\\ dd\\,\\ yyyy", true, false},
+		{"hh:mm:ss", false, true},
+		{"[h]:mm:ss", false, true},
+		{"mm:ss.0", false, true},
+		{"yyyy-mm-dd hh:mm:ss.000", true, true},
+		{"[Red]yyyy/m/d", true, false},
+		{"m/d/yy h:mm AM/PM", true, true},
+	}
+	for _, c := range cases {
+		hasDate, hasTime := dateFormatParts(c.numFmt)
+		if hasDate != c.hasDate || hasTime != c.hasTime {
+			t.Errorf("dateFormatParts(%q) = %v, %v, want %v, %v", c.numFmt, hasDate, hasTime, c.hasDate, c.hasTime)
+		}
+	}
+}
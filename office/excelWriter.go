@@ -0,0 +1,529 @@
+package office
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tealeg/xlsx"
+)
+
+// 内置样式：0 默认，1 表头
+const (
+	styleDefault = 0
+	styleHeader  = 1
+	// 自定义数字格式编号从 164 开始
+	firstCustomNumFmt = 164
+	// 数据验证作用到的最后一行
+	maxExcelRow = 1048576
+)
+
+// 未指定 numfmt 时时间字段的格式
+const defaultTimeNumFmt = "yyyy-mm-dd hh:mm:ss"
+
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+// 流式写 xlsx，每行写入后即输出到 io.Writer，适合 HTTP 下载等大数据量导出
+//
+//	w := office.NewExcelWriter(out)
+//	w.AddSheet("用户", User{})
+//	for rows.Next() { w.Write(user) }
+//	w.Close()
+//
+// 表头、列宽、数字格式来自结构体的 xlsx 标签，见 Sheet.Unmarshal
+type ExcelWriter struct {
+	zw     *zip.Writer
+	sheets []string
+	err    error
+
+	// 当前工作表
+	buf      *bufio.Writer
+	rowType  reflect.Type
+	rules    []fieldRule
+	styles   []int // 每列的样式
+	rowNum   int
+	template bool
+
+	numFmts []string       // 自定义数字格式，下标 + firstCustomNumFmt 为编号
+	xfs     []int          // 样式 2 起对应的数字格式编号
+	xfIndex map[string]int // 数字格式 -> 样式编号
+}
+
+func NewExcelWriter(out io.Writer) *ExcelWriter {
+	return &ExcelWriter{zw: zip.NewWriter(out), xfIndex: map[string]int{}}
+}
+
+// 导出到 out，rows 为结构体切片，单个工作表
+func ExportExcel(out io.Writer, sheetName string, rows interface{}) error {
+	w := NewExcelWriter(out)
+	if err := w.WriteSheet(sheetName, rows); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// 生成空白导入模板：只有表头，选中单元格时提示填写要求，oneof 字段提供下拉选项
+func ExcelTemplate(out io.Writer, sheetName string, model interface{}) error {
+	w := NewExcelWriter(out)
+	if err := w.addSheet(sheetName, model, true); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// 问答导入模板，与 ExcelToContent 读取的格式一致
+func ExcelQaTemplate(out io.Writer) error {
+	return ExcelTemplate(out, "问答", ExcelRes{})
+}
+
+// 新建工作表并写入表头，model 为结构体、结构体指针或结构体切片，之后用 Write 逐行写入
+func (w *ExcelWriter) AddSheet(name string, model interface{}) error {
+	return w.addSheet(name, model, false)
+}
+
+// 新建工作表并写入 rows 中的所有行
+func (w *ExcelWriter) WriteSheet(name string, rows interface{}) error {
+	if err := w.AddSheet(name, rows); err != nil {
+		return err
+	}
+	rv := reflect.Indirect(reflect.ValueOf(rows))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return w.fail(errors.New("rows 必须是切片！"))
+	}
+	for i := 0; i < rv.Len(); i++ {
+		if err := w.Write(rv.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 向当前工作表写入一行，row 的类型须与 AddSheet 时一致
+func (w *ExcelWriter) Write(row interface{}) error {
+	if w.err != nil {
+		return w.err
+	}
+	if w.buf == nil {
+		return w.fail(errors.New("请先调用 AddSheet！"))
+	}
+	rv := reflect.ValueOf(row)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return w.fail(errors.New("row 不能为 nil！"))
+		}
+		rv = rv.Elem()
+	}
+	if rv.Type() != w.rowType {
+		return w.fail(errors.New("row 类型应为 " + w.rowType.String() + "！"))
+	}
+
+	w.rowNum++
+	fmt.Fprintf(w.buf, `<row r="%d">`, w.rowNum)
+	for i, rule := range w.rules {
+		w.writeValue(i, rv.FieldByIndex(rule.index))
+	}
+	w.buf.WriteString(`</row>`)
+	return w.err
+}
+
+// 结束当前工作表，写入工作簿结构并关闭 zip，不会关闭 out
+func (w *ExcelWriter) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if len(w.sheets) == 0 {
+		return w.fail(errors.New("没有工作表！"))
+	}
+	if err := w.endSheet(); err != nil {
+		return err
+	}
+	parts := []struct {
+		name string
+		data string
+	}{
+		{"[Content_Types].xml", w.contentTypes()},
+		{"_rels/.rels", `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+			`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`},
+		{"xl/workbook.xml", w.workbook()},
+		{"xl/_rels/workbook.xml.rels", w.workbookRels()},
+		{"xl/styles.xml", w.stylesXml()},
+	}
+	for _, p := range parts {
+		f, err := w.zw.Create(p.name)
+		if err != nil {
+			return w.fail(err)
+		}
+		if _, err := io.WriteString(f, p.data); err != nil {
+			return w.fail(err)
+		}
+	}
+	return w.fail(w.zw.Close())
+}
+
+func (w *ExcelWriter) fail(err error) error {
+	if w.err == nil {
+		w.err = err
+	}
+	return w.err
+}
+
+func (w *ExcelWriter) addSheet(name string, model interface{}, template bool) error {
+	if w.err != nil {
+		return w.err
+	}
+	t := reflect.TypeOf(model)
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return w.fail(errors.New("model 必须是结构体！"))
+	}
+	rules, err := parseFieldRules(t)
+	if err != nil {
+		return w.fail(err)
+	}
+
+	name = sheetName(name, len(w.sheets)+1)
+	for _, s := range w.sheets {
+		if strings.EqualFold(s, name) {
+			return w.fail(errors.New("工作表 " + name + " 重复！"))
+		}
+	}
+	if len(w.sheets) > 0 {
+		if err := w.endSheet(); err != nil {
+			return err
+		}
+	}
+	w.sheets = append(w.sheets, name)
+	f, err := w.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", len(w.sheets)))
+	if err != nil {
+		return w.fail(err)
+	}
+	w.buf = bufio.NewWriter(f)
+	w.rowType, w.rules, w.template = t, rules, template
+
+	w.styles = make([]int, len(rules))
+	for i, rule := range rules {
+		numfmt := rule.numfmt
+		ft := rule.fieldType(t)
+		if numfmt == "" && ft == timeType {
+			numfmt = defaultTimeNumFmt
+		}
+		w.styles[i] = w.numFmtStyle(numfmt)
+	}
+
+	w.buf.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">`)
+	// 冻结表头
+	w.buf.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	w.buf.WriteString(`<cols>`)
+	for i, rule := range rules {
+		width := rule.width
+		if width == 0 {
+			// 按表头长度估算，中文按两个字符宽
+			width = math.Max(10, float64(displayWidth(rule.column.Headers[0]))+2)
+		}
+		fmt.Fprintf(w.buf, `<col min="%d" max="%d" width="%s" customWidth="1"/>`, i+1, i+1, strconv.FormatFloat(width, 'f', -1, 64))
+	}
+	w.buf.WriteString(`</cols><sheetData>`)
+
+	w.rowNum = 1
+	w.buf.WriteString(`<row r="1">`)
+	for i, rule := range rules {
+		w.writeString(i, rule.column.Headers[0], styleHeader)
+	}
+	w.buf.WriteString(`</row>`)
+	return nil
+}
+
+// 结束当前工作表
+func (w *ExcelWriter) endSheet() error {
+	w.buf.WriteString(`</sheetData>`)
+	if w.template {
+		w.writeValidations()
+	}
+	w.buf.WriteString(`</worksheet>`)
+	if err := w.buf.Flush(); err != nil {
+		return w.fail(err)
+	}
+	w.buf = nil
+	return nil
+}
+
+// 模板的填写提示和下拉选项
+func (w *ExcelWriter) writeValidations() {
+	var list []string
+	for i, rule := range w.rules {
+		prompt := rule.prompt()
+		if prompt == "" && len(rule.oneof) == 0 {
+			continue
+		}
+		col := xlsx.ColIndexToLetters(i)
+		v := `<dataValidation allowBlank="1" showInputMessage="1"`
+		if len(rule.oneof) > 0 {
+			v = `<dataValidation type="list" allowBlank="1" showInputMessage="1" showErrorMessage="1"`
+		}
+		v += fmt.Sprintf(` promptTitle="%s" prompt="%s" sqref="%s2:%s%d">`,
+			xmlEscape(truncateRunes(rule.column.Headers[0], 32)), xmlEscape(truncateRunes(prompt, 255)), col, col, maxExcelRow)
+		if len(rule.oneof) > 0 {
+			v += `<formula1>"` + xmlEscape(strings.Join(rule.oneof, ",")) + `"</formula1>`
+		}
+		list = append(list, v+`</dataValidation>`)
+	}
+	if len(list) > 0 {
+		fmt.Fprintf(w.buf, `<dataValidations count="%d">%s</dataValidations>`, len(list), strings.Join(list, ""))
+	}
+}
+
+// 模板中的填写提示
+func (r fieldRule) prompt() string {
+	var list []string
+	if r.hint != "" {
+		list = append(list, r.hint)
+	}
+	if r.required {
+		list = append(list, "必填")
+	}
+	if len(r.oneof) > 0 {
+		list = append(list, "可选："+strings.Join(r.oneof, "、"))
+	}
+	if r.min != nil {
+		list = append(list, "最小 "+strconv.FormatFloat(*r.min, 'f', -1, 64))
+	}
+	if r.max != nil {
+		list = append(list, "最大 "+strconv.FormatFloat(*r.max, 'f', -1, 64))
+	}
+	if r.format != "" {
+		list = append(list, "格式 "+r.format)
+	}
+	return strings.Join(list, "；")
+}
+
+// 字段的实际类型，去掉指针
+func (r fieldRule) fieldType(t reflect.Type) reflect.Type {
+	ft := t.FieldByIndex(r.index).Type
+	for ft.Kind() == reflect.Ptr {
+		ft = ft.Elem()
+	}
+	return ft
+}
+
+func (w *ExcelWriter) numFmtStyle(numfmt string) int {
+	if numfmt == "" {
+		return styleDefault
+	}
+	if s, ok := w.xfIndex[numfmt]; ok {
+		return s
+	}
+	w.numFmts = append(w.numFmts, numfmt)
+	w.xfs = append(w.xfs, firstCustomNumFmt+len(w.numFmts)-1)
+	s := styleHeader + len(w.xfs)
+	w.xfIndex[numfmt] = s
+	return s
+}
+
+func (w *ExcelWriter) writeValue(col int, v reflect.Value) {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	style := w.styles[col]
+
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if !t.IsZero() {
+			w.writeNumber(col, excelTime(t), style)
+		}
+		return
+	}
+	if m, ok := textMarshaler(v); ok {
+		text, err := m.MarshalText()
+		if err != nil {
+			w.fail(err)
+			return
+		}
+		w.writeString(col, string(text), style)
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		if v.Len() > 0 {
+			w.writeString(col, v.String(), style)
+		}
+	case reflect.Bool:
+		b := "0"
+		if v.Bool() {
+			b = "1"
+		}
+		fmt.Fprintf(w.buf, `<c r="%s%d" t="b"%s><v>%s</v></c>`, xlsx.ColIndexToLetters(col), w.rowNum, styleAttr(style), b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		fmt.Fprintf(w.buf, `<c r="%s%d"%s><v>%d</v></c>`, xlsx.ColIndexToLetters(col), w.rowNum, styleAttr(style), v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		fmt.Fprintf(w.buf, `<c r="%s%d"%s><v>%d</v></c>`, xlsx.ColIndexToLetters(col), w.rowNum, styleAttr(style), v.Uint())
+	case reflect.Float32, reflect.Float64:
+		w.writeNumber(col, v.Float(), style)
+	default:
+		if s, ok := v.Interface().(fmt.Stringer); ok {
+			w.writeString(col, s.String(), style)
+			return
+		}
+		w.fail(errors.New("不支持导出的字段类型 " + v.Type().String() + "！"))
+	}
+}
+
+func (w *ExcelWriter) writeNumber(col int, n float64, style int) {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return
+	}
+	fmt.Fprintf(w.buf, `<c r="%s%d"%s><v>%s</v></c>`, xlsx.ColIndexToLetters(col), w.rowNum, styleAttr(style), strconv.FormatFloat(n, 'f', -1, 64))
+}
+
+func (w *ExcelWriter) writeString(col int, s string, style int) {
+	fmt.Fprintf(w.buf, `<c r="%s%d" t="inlineStr"%s><is><t xml:space="preserve">%s</t></is></c>`,
+		xlsx.ColIndexToLetters(col), w.rowNum, styleAttr(style), xmlEscape(s))
+}
+
+func textMarshaler(v reflect.Value) (encoding.TextMarshaler, bool) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok {
+		return m, true
+	}
+	if v.CanAddr() {
+		if m, ok := v.Addr().Interface().(encoding.TextMarshaler); ok {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+func styleAttr(style int) string {
+	if style == styleDefault {
+		return ""
+	}
+	return ` s="` + strconv.Itoa(style) + `"`
+}
+
+// 时间转为 Excel 日期序号，按本地时间的年月日时分秒计算
+func excelTime(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(excelEpoch).Hours() / 24
+}
+
+// 工作表名最长 31 个字符，不能包含 []:*?/\
+func sheetName(name string, n int) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.TrimSpace(name))
+	name = truncateRunes(name, 31)
+	if name == "" {
+		name = "Sheet" + strconv.Itoa(n)
+	}
+	return name
+}
+
+func truncateRunes(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// 显示宽度，非 ASCII 字符按两个字符计
+func displayWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		if r < 0x80 {
+			n++
+		} else {
+			n += 2
+		}
+	}
+	return n
+}
+
+func xmlEscape(s string) string {
+	var sb strings.Builder
+	xml.EscapeText(&sb, []byte(s))
+	return sb.String()
+}
+
+func (w *ExcelWriter) contentTypes() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	for i := range w.sheets {
+		fmt.Fprintf(&sb, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+	}
+	sb.WriteString(`</Types>`)
+	return sb.String()
+}
+
+func (w *ExcelWriter) workbook() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets>`)
+	for i, name := range w.sheets {
+		fmt.Fprintf(&sb, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, xmlEscape(name), i+1, i+1)
+	}
+	sb.WriteString(`</sheets></workbook>`)
+	return sb.String()
+}
+
+func (w *ExcelWriter) workbookRels() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">`)
+	for i := range w.sheets {
+		fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, i+1)
+	}
+	fmt.Fprintf(&sb, `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>`, len(w.sheets)+1)
+	sb.WriteString(`</Relationships>`)
+	return sb.String()
+}
+
+func (w *ExcelWriter) stylesXml() string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` +
+		`<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	if len(w.numFmts) > 0 {
+		fmt.Fprintf(&sb, `<numFmts count="%d">`, len(w.numFmts))
+		for i, f := range w.numFmts {
+			fmt.Fprintf(&sb, `<numFmt numFmtId="%d" formatCode="%s"/>`, firstCustomNumFmt+i, xmlEscape(f))
+		}
+		sb.WriteString(`</numFmts>`)
+	}
+	sb.WriteString(`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+		`<fills count="3"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill>` +
+		`<fill><patternFill patternType="solid"><fgColor rgb="FFD9E1F2"/><bgColor indexed="64"/></patternFill></fill></fills>` +
+		`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+		`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>`)
+	fmt.Fprintf(&sb, `<cellXfs count="%d">`, 2+len(w.xfs))
+	sb.WriteString(`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+		`<xf numFmtId="0" fontId="1" fillId="2" borderId="0" xfId="0" applyFont="1" applyFill="1"/>`)
+	for _, id := range w.xfs {
+		fmt.Fprintf(&sb, `<xf numFmtId="%d" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>`, id)
+	}
+	sb.WriteString(`</cellXfs><cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles></styleSheet>`)
+	return sb.String()
+}
@@ -0,0 +1,234 @@
+package office
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bangongyi/toolkits/document"
+	"github.com/bangongyi/toolkits/pdf"
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// 待提取的文件，Path、URL、Reader 三选一
+type Source struct {
+	Path   string    // 本地文件路径
+	URL    string    // 远程文件地址
+	Reader io.Reader // 文件内容
+	Name   string    // 文件名，为空时取路径或地址中的文件名，只用于区分 csv、html、markdown 和 txt
+}
+
+// 提取选项
+type ExtractOptions struct {
+	Images bool            // 提取 docx/pptx/xlsx 中的图片，作为图片块插入到所在位置
+	Ocr    ImageRecognizer // 识别图片中的文字填入图片块，不为空时 Images 视为 true
+}
+
+// 统一的提取入口，按文件内容识别格式后调用对应的解析方法，ctx 用于取消下载
+func Extract(ctx context.Context, source Source) (*document.Document, error) {
+	return ExtractWithOptions(ctx, source, ExtractOptions{})
+}
+
+// 按选项提取，ctx 用于取消下载和图片识别
+func ExtractWithOptions(ctx context.Context, source Source, opts ExtractOptions) (*document.Document, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	src := document.Source{Name: source.Name, Path: source.Path, URL: source.URL}
+	filePath := source.Path
+	switch {
+	case source.Path != "":
+		if src.Name == "" {
+			src.Name = filepath.Base(source.Path)
+		}
+	case source.URL != "", source.Reader != nil:
+		if src.Name == "" && source.URL != "" {
+			src.Name = urlFileName(source.URL)
+		}
+		ws, err := workspace.New("")
+		if err != nil {
+			return nil, errors.New("创建临时目录失败！")
+		}
+		defer ws.Cleanup()
+
+		if source.URL != "" {
+			filePath, err = ws.DownloadContext(ctx, source.URL, nameSuffix(src.Name))
+		} else {
+			filePath, err = ws.Save(source.Reader, nameSuffix(src.Name))
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, errors.New("文件保存在本地失败！")
+		}
+	default:
+		return nil, errors.New("没有指定文件来源！")
+	}
+	src.Suffix = nameSuffix(src.Name)
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, errors.New("计算文件大小失败！")
+	}
+	src.Size = size
+
+	format, err := detectFormat(filePath, src.Suffix)
+	if err != nil {
+		return nil, err
+	}
+	src.Format = format
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	doc, err := extractDocument(filePath, src)
+	if err != nil {
+		return nil, err
+	}
+	doc.Source = src
+	// 属性信息损坏不影响正文提取
+	if meta, err := readMeta(filePath, format); err == nil {
+		doc.Meta = meta
+	}
+
+	if opts.Images || opts.Ocr != nil {
+		images, err := embeddedImages(filePath, format)
+		if err != nil {
+			return nil, err
+		}
+		if opts.Ocr != nil {
+			if err := recognizeImages(ctx, images, opts.Ocr); err != nil {
+				return nil, err
+			}
+		}
+		insertImages(doc, images)
+	}
+	return doc, nil
+}
+
+func extractDocument(filePath string, src document.Source) (*document.Document, error) {
+	switch src.Format {
+	case FormatDocx, FormatDoc:
+		doc, err := parseWord(filePath, WordOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return doc.Document(), nil
+	case FormatXlsx:
+		book, err := parseExcel(filePath, ExcelOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return book.Document(), nil
+	case FormatXls:
+		book, err := parseLegacyExcel(filePath, ExcelOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return book.Document(), nil
+	case FormatCsv:
+		book, err := parseCsv(filePath, csvSheetName(src.Name), src.Suffix, CsvOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return book.Document(), nil
+	case FormatPptx, FormatPpt:
+		pres, err := parsePpt(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return pres.Document(), nil
+	case FormatOdt:
+		doc, err := parseOdt(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Document(), nil
+	case FormatOds:
+		book, err := parseOds(filePath, ExcelOptions{})
+		if err != nil {
+			return nil, err
+		}
+		return book.Document(), nil
+	case FormatOdp:
+		pres, err := parseOdp(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return pres.Document(), nil
+	case FormatHtml:
+		doc, err := parseHtml(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Document(), nil
+	case FormatMd:
+		doc, err := parseMarkdown(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Document(), nil
+	case FormatRtf:
+		doc, err := parseRtf(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Document(), nil
+	case FormatEpub:
+		doc, err := parseEpub(filePath)
+		if err != nil {
+			return nil, err
+		}
+		return doc.Document(), nil
+	case FormatTxt:
+		text, err := parseTxt(filePath, TxtOptions{PreserveLines: true})
+		if err != nil {
+			return nil, err
+		}
+		page := document.Page{Kind: document.PageKindPage, Number: 1, Blocks: document.TextBlocks(text)}
+		return &document.Document{Pages: []document.Page{page}}, nil
+	case FormatPdf:
+		return pdfDocument(filePath)
+	}
+	return nil, errUnsupportedFormat
+}
+
+// pdf 只读取文本层，扫描页没有内容
+func pdfDocument(filePath string) (*document.Document, error) {
+	reader, err := pdf.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	list, err := reader.Pages()
+	if err != nil {
+		return nil, err
+	}
+	pages := make([]document.Page, 0, len(list))
+	for _, p := range list {
+		pages = append(pages, document.Page{Kind: document.PageKindPage, Number: p.Number, Blocks: document.TextBlocks(p.Text)})
+	}
+	return &document.Document{Pages: pages}, nil
+}
+
+// 地址路径中的文件名，不含查询参数
+func urlFileName(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	name := path.Base(u.Path)
+	if name == "/" || name == "." {
+		return ""
+	}
+	return name
+}
+
+// 文件名的小写后缀，不含点号
+func nameSuffix(name string) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(name), "."))
+}
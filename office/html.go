@@ -0,0 +1,342 @@
+package office
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+	"golang.org/x/net/html/charset"
+)
+
+// html 按 DOM 顺序转为与 word 相同的结构：h1-h6 为标题，ul/ol 为列表，table 为表格，
+// 其余块级元素各为一个段落；脚本、样式等不显示的内容跳过。
+
+// html文件转结构化内容，编码按 BOM、meta 声明识别，没有声明时自动检测
+func HtmlToStructure(filePath string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return fileToWord(filePath, parseHtml)
+}
+
+// html地址文件转结构化内容
+func HtmlUrlToStructure(url string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return urlToWord(url, parseHtml)
+}
+
+func parseHtml(filePath string) (*WordDocument, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	blocks, err := htmlToBlocks(data)
+	if err != nil {
+		return nil, err
+	}
+	return &WordDocument{Blocks: blocks}, nil
+}
+
+func htmlToBlocks(data []byte) ([]WordBlock, error) {
+	text, err := decodeHtml(data)
+	if err != nil {
+		return nil, err
+	}
+	root, err := html.Parse(strings.NewReader(text))
+	if err != nil {
+		return nil, errors.New("解析html失败！")
+	}
+	b := &htmlBuilder{}
+	b.children(root, false, false)
+	b.flush()
+	return b.blocks, nil
+}
+
+// 按 BOM 和 meta 声明的编码解码；没有声明时 charset 包默认为 windows-1252，改为自动检测中文编码
+func decodeHtml(data []byte) (string, error) {
+	enc, name, _ := charset.DetermineEncoding(data, "text/html")
+	if name != "windows-1252" {
+		out, err := enc.NewDecoder().Bytes(data)
+		if err != nil {
+			return "", errors.New("文本解码失败！")
+		}
+		return strings.TrimPrefix(string(out), "\ufeff"), nil
+	}
+	text, _, err := decodeText(data, "")
+	return text, err
+}
+
+// 不显示的元素
+var htmlSkipTags = map[atom.Atom]bool{
+	atom.Head: true, atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Template: true,
+	atom.Iframe: true, atom.Object: true, atom.Svg: true, atom.Math: true, atom.Select: true, atom.Button: true,
+}
+
+// 块级元素，前后断开段落
+var htmlBlockTags = map[atom.Atom]bool{
+	atom.P: true, atom.Div: true, atom.Section: true, atom.Article: true, atom.Header: true, atom.Footer: true,
+	atom.Main: true, atom.Aside: true, atom.Nav: true, atom.Blockquote: true, atom.Figure: true, atom.Figcaption: true,
+	atom.Address: true, atom.Dl: true, atom.Dt: true, atom.Dd: true, atom.Form: true, atom.Fieldset: true,
+	atom.Details: true, atom.Summary: true, atom.Hr: true, atom.Caption: true, atom.Center: true, atom.Body: true,
+}
+
+var htmlHeadings = map[atom.Atom]int{atom.H1: 1, atom.H2: 2, atom.H3: 3, atom.H4: 4, atom.H5: 5, atom.H6: 6}
+
+// 正在输出的列表
+type htmlList struct {
+	ordered bool
+	count   int
+}
+
+type htmlBuilder struct {
+	blocks []WordBlock
+	spans  []WordSpan
+	para   WordParagraph // 当前段落的标题、列表属性
+	lists  []*htmlList
+	pre    int // 所在 pre 的层数，pre 中保留空白
+}
+
+func (b *htmlBuilder) children(n *html.Node, bold bool, italic bool) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		b.node(c, bold, italic)
+	}
+}
+
+func (b *htmlBuilder) node(n *html.Node, bold bool, italic bool) {
+	switch n.Type {
+	case html.TextNode:
+		b.text(n.Data, bold, italic)
+		return
+	case html.ElementNode:
+	default:
+		b.children(n, bold, italic)
+		return
+	}
+	if htmlSkipTags[n.DataAtom] || htmlAttr(n, "hidden") != "" {
+		return
+	}
+	if level, ok := htmlHeadings[n.DataAtom]; ok {
+		b.flush()
+		b.para = WordParagraph{HeadingLevel: level}
+		b.children(n, bold, italic)
+		b.flush()
+		return
+	}
+	switch n.DataAtom {
+	case atom.Br:
+		b.spans = append(b.spans, WordSpan{Text: "\n", Bold: bold, Italic: italic})
+	case atom.B, atom.Strong:
+		b.children(n, true, italic)
+	case atom.I, atom.Em:
+		b.children(n, bold, true)
+	case atom.Ul, atom.Ol, atom.Menu:
+		b.flush()
+		list := &htmlList{ordered: n.DataAtom == atom.Ol}
+		if start, err := strconv.Atoi(htmlAttr(n, "start")); err == nil {
+			list.count = start - 1
+		}
+		b.lists = append(b.lists, list)
+		b.children(n, bold, italic)
+		b.lists = b.lists[:len(b.lists)-1]
+		b.flush()
+	case atom.Li:
+		b.flush()
+		if len(b.lists) > 0 {
+			list := b.lists[len(b.lists)-1]
+			list.count++
+			b.para = WordParagraph{IsList: true, ListLevel: len(b.lists) - 1, Ordered: list.ordered, Numbering: "•"}
+			if list.ordered {
+				b.para.Numbering = strconv.Itoa(list.count) + "."
+			}
+		}
+		b.children(n, bold, italic)
+		b.flush()
+	case atom.Table:
+		b.flush()
+		if table := b.table(n); table != nil {
+			b.blocks = append(b.blocks, WordBlock{Table: table})
+		}
+	case atom.Pre:
+		b.flush()
+		b.pre++
+		b.children(n, bold, italic)
+		b.flush()
+		b.pre--
+	default:
+		if htmlBlockTags[n.DataAtom] {
+			b.flush()
+			b.children(n, bold, italic)
+			b.flush()
+			return
+		}
+		b.children(n, bold, italic)
+	}
+}
+
+// 文字按浏览器的方式合并空白，pre 中原样保留
+func (b *htmlBuilder) text(s string, bold bool, italic bool) {
+	if b.pre == 0 && s != "" {
+		lead, trail := isHtmlSpace(s[0]), isHtmlSpace(s[len(s)-1])
+		s = strings.Join(strings.Fields(s), " ")
+		if lead {
+			s = " " + s
+		}
+		if trail && s != " " {
+			s += " "
+		}
+		// 与前面的空白合并，段落开头的空白去掉
+		if strings.HasPrefix(s, " ") && b.endsWithSpace() {
+			s = s[1:]
+		}
+	}
+	if s != "" {
+		b.spans = append(b.spans, WordSpan{Text: s, Bold: bold, Italic: italic})
+	}
+}
+
+func (b *htmlBuilder) endsWithSpace() bool {
+	if len(b.spans) == 0 {
+		return true
+	}
+	t := b.spans[len(b.spans)-1].Text
+	return strings.HasSuffix(t, " ") || strings.HasSuffix(t, "\n")
+}
+
+func isHtmlSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+// 结束当前段落，空段落丢弃
+func (b *htmlBuilder) flush() {
+	spans := mergeSpans(b.spans)
+	b.spans = nil
+	para := b.para
+	b.para = WordParagraph{}
+	// 去掉首尾的空白，pre 中只去掉首尾的换行
+	if cut := " \n"; len(spans) > 0 {
+		if b.pre > 0 {
+			cut = "\n"
+		}
+		spans[0].Text = strings.TrimLeft(spans[0].Text, cut)
+		spans[len(spans)-1].Text = strings.TrimRight(spans[len(spans)-1].Text, cut)
+		spans = mergeSpans(spans)
+	}
+	for _, s := range spans {
+		para.Text += s.Text
+	}
+	if strings.TrimSpace(para.Text) == "" {
+		return
+	}
+	para.Spans = spans
+	b.blocks = append(b.blocks, WordBlock{Paragraph: &para})
+}
+
+// 表格转为与 word 相同的结构，rowspan 覆盖的单元格标记为 Merged
+func (b *htmlBuilder) table(n *html.Node) *WordTable {
+	t := &WordTable{}
+	type vSpan struct {
+		pos  cellPos
+		left int
+	}
+	vMerge := map[int]*vSpan{}
+	// 纵向合并覆盖的列，补上 Merged 单元格
+	covered := func(row *WordRow, col int) int {
+		for {
+			v, ok := vMerge[col]
+			if !ok || v.left == 0 {
+				return col
+			}
+			origin := t.Rows[v.pos.row].Cells[v.pos.cell]
+			row.Cells = append(row.Cells, WordCell{Col: col, ColSpan: origin.ColSpan, Merged: true})
+			v.left--
+			col += origin.ColSpan
+		}
+	}
+	for _, tr := range htmlRows(n) {
+		r := len(t.Rows)
+		row := WordRow{}
+		col := 0
+		for c := tr.FirstChild; c != nil; c = c.NextSibling {
+			if c.Type != html.ElementNode || (c.DataAtom != atom.Td && c.DataAtom != atom.Th) {
+				continue
+			}
+			col = covered(&row, col)
+			sub := &htmlBuilder{}
+			sub.children(c, c.DataAtom == atom.Th, false)
+			sub.flush()
+			cell := WordCell{Col: col, ColSpan: htmlSpan(c, "colspan"), RowSpan: htmlSpan(c, "rowspan"), Blocks: sub.blocks}
+			cell.Text = blocksText(cell.Blocks)
+			if cell.RowSpan > 1 {
+				vMerge[col] = &vSpan{pos: cellPos{row: r, cell: len(row.Cells)}, left: cell.RowSpan - 1}
+			}
+			row.Cells = append(row.Cells, cell)
+			col += cell.ColSpan
+		}
+		col = covered(&row, col)
+		t.Rows = append(t.Rows, row)
+		t.Cols = maxInt(t.Cols, col)
+	}
+	if len(t.Rows) == 0 {
+		return nil
+	}
+	return t
+}
+
+// 表格的行，含 thead/tbody/tfoot 中的行，不含嵌套表格的行
+func htmlRows(n *html.Node) []*html.Node {
+	var rows []*html.Node
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.DataAtom {
+		case atom.Tr:
+			rows = append(rows, c)
+		case atom.Thead, atom.Tbody, atom.Tfoot:
+			rows = append(rows, htmlRows(c)...)
+		}
+	}
+	return rows
+}
+
+// 合并的行列数，缺省或无效时为 1，最多 1000
+func htmlSpan(n *html.Node, name string) int {
+	v, err := strconv.Atoi(strings.TrimSpace(htmlAttr(n, name)))
+	if err != nil || v < 1 {
+		return 1
+	}
+	return minInt(v, 1000)
+}
+
+func htmlAttr(n *html.Node, name string) string {
+	for _, a := range n.Attr {
+		if a.Namespace == "" && a.Key == name {
+			if a.Val == "" {
+				// 布尔属性，如 hidden
+				return name
+			}
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func isHtmlSuffix(suffix string) bool {
+	switch strings.ToLower(suffix) {
+	case "html", "htm", "xhtml":
+		return true
+	}
+	return false
+}
+
+// 文件开头是否为 html 文档
+func looksLikeHtml(head []byte) bool {
+	head = bytes.TrimPrefix(head, bomUTF8)
+	head = bytes.ToLower(bytes.TrimSpace(head))
+	if bytes.HasPrefix(head, []byte("<?xml")) {
+		if i := bytes.Index(head, []byte("?>")); i >= 0 {
+			head = bytes.TrimSpace(head[i+2:])
+		}
+	}
+	return bytes.HasPrefix(head, []byte("<!doctype html")) || bytes.HasPrefix(head, []byte("<html"))
+}
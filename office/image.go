@@ -0,0 +1,354 @@
+package office
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/tealeg/xlsx"
+
+	"github.com/bangongyi/toolkits/document"
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// docx/pptx/xlsx 中的图片按关系ID从各部件的 rels 找到 media 下的图片文件，
+// 位置记为所在页和插入到第几个块之前，与 Document() 转换出的块一一对应。
+
+const (
+	relImage   = "/image"
+	relDrawing = "/drawing"
+
+	nsWordml    = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsDrawingml = "http://schemas.openxmlformats.org/drawingml/2006/main"
+	nsVml       = "urn:schemas-microsoft-com:vml"
+	nsRel       = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+)
+
+// OCR 支持的图片格式，emf/wmf 等矢量图只提取不识别
+var ocrImageTypes = map[string]bool{
+	"image/png": true, "image/jpeg": true, "image/bmp": true, "image/gif": true, "image/tiff": true,
+}
+
+// 文件中嵌入的图片
+type EmbeddedImage struct {
+	Name        string `json:"name"`             // 包内路径，如 word/media/image1.png
+	ContentType string `json:"content_type"`     // 如 image/png
+	Data        []byte `json:"-"`                // 图片内容
+	Page        int    `json:"page"`             // 所在页、幻灯片或工作表的序号，从 1 开始
+	Block       int    `json:"block"`            // 插入到该页第几个块之前，从 0 开始
+	Anchor      string `json:"anchor,omitempty"` // excel 中图片左上角所在的单元格，如 B3
+	Text        string `json:"text,omitempty"`   // 识别出的文字
+}
+
+// 图片文字识别，*baidu.BaiduOcr 满足该接口
+type ImageRecognizer interface {
+	ImageToWord(filePath string) (word string, fileSuffix string, FileSize int, err error)
+}
+
+// 提取 docx/pptx/xlsx 中嵌入的图片，其他格式返回空列表
+func ExtractImages(filePath string) ([]EmbeddedImage, error) {
+	suffix, _ := getSuffix(filePath)
+	format, err := detectFormat(filePath, strings.ToLower(suffix))
+	if err != nil {
+		return nil, err
+	}
+	return embeddedImages(filePath, format)
+}
+
+func embeddedImages(filePath string, format string) ([]EmbeddedImage, error) {
+	if format != FormatDocx && format != FormatPptx && format != FormatXlsx {
+		return nil, nil
+	}
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	var images []EmbeddedImage
+	switch format {
+	case FormatDocx:
+		images, err = docxImages(pkg)
+	case FormatPptx:
+		images, err = pptxImages(pkg)
+	case FormatXlsx:
+		images, err = xlsxImages(pkg)
+	}
+	if err != nil {
+		return nil, errors.New("读取图片失败！")
+	}
+	return images, nil
+}
+
+// 按关系ID读取图片，外部链接和缺失的图片返回 false
+func readImage(pkg *ooxmlPackage, targets map[string]string, rid string) (EmbeddedImage, bool) {
+	part, ok := targets[rid]
+	if !ok {
+		return EmbeddedImage{}, false
+	}
+	data, err := pkg.read(part)
+	if err != nil {
+		return EmbeddedImage{}, false
+	}
+	return EmbeddedImage{Name: part, ContentType: pkg.contentType(part), Data: data}, true
+}
+
+// 部件中图片关系ID -> 图片部件
+func imageTargets(pkg *ooxmlPackage, part string) map[string]string {
+	targets := map[string]string{}
+	for _, r := range pkg.rels(part) {
+		if strings.HasSuffix(r.Type, relImage) && r.TargetMode != "External" {
+			targets[r.Id] = r.Target
+		}
+	}
+	return targets
+}
+
+// word 正文中的图片。块的计数与正文提取（接受修订）一致：有文字的段落和有行的表格各算一块，
+// 图片位于所在段落之后；文本框和删除修订中的文字不计入所在段落
+func docxImages(pkg *ooxmlPackage) ([]EmbeddedImage, error) {
+	main := pkg.mainPart("word/document.xml")
+	targets := imageTargets(pkg, main)
+	rc, err := pkg.open(main)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var images, pending []EmbeddedImage
+	blocks := 0
+	depth, blockDepth, textBoxDepth, rowSdtDepth := 0, 0, 0, 0
+	blockIsTable, hasContent, inText := false, false, false
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			depth++
+			switch {
+			case t.Name.Space == nsMc && t.Name.Local == "Fallback",
+				t.Name.Space == nsWordml && (t.Name.Local == "del" || t.Name.Local == "moveFrom"):
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+				depth--
+			case t.Name.Space == nsWordml && (t.Name.Local == "p" || t.Name.Local == "tbl") && blockDepth == 0:
+				blockDepth, blockIsTable, hasContent = depth, t.Name.Local == "tbl", false
+			case blockIsTable && t.Name.Space == nsWordml && t.Name.Local == "sdtContent" && depth == blockDepth+2:
+				rowSdtDepth = depth
+			case blockIsTable && t.Name.Space == nsWordml && t.Name.Local == "tr":
+				// 与 tableRows 一致，只算表格的直接子行和内容控件中的行
+				if depth == blockDepth+1 || (rowSdtDepth > 0 && depth == rowSdtDepth+1) {
+					hasContent = true
+				}
+			case t.Name.Space == nsWordml && t.Name.Local == "txbxContent" && textBoxDepth == 0:
+				textBoxDepth = depth
+			case t.Name.Space == nsWordml && t.Name.Local == "t":
+				inText = textBoxDepth == 0 && !blockIsTable
+			case t.Name.Space == nsDrawingml && t.Name.Local == "blip":
+				if img, ok := readImage(pkg, targets, xmlAttrNs(t, nsRel, "embed")); ok {
+					pending = append(pending, img)
+				}
+			case t.Name.Space == nsVml && t.Name.Local == "imagedata":
+				if img, ok := readImage(pkg, targets, xmlAttrNs(t, nsRel, "id")); ok {
+					pending = append(pending, img)
+				}
+			}
+		case xml.EndElement:
+			switch {
+			case depth == blockDepth:
+				if hasContent {
+					blocks++
+				}
+				blockDepth, blockIsTable = 0, false
+			case depth == rowSdtDepth:
+				rowSdtDepth = 0
+			case depth == textBoxDepth:
+				textBoxDepth = 0
+			}
+			if blockDepth == 0 {
+				for _, img := range pending {
+					img.Page, img.Block = 1, blocks
+					images = append(images, img)
+				}
+				pending = pending[:0]
+			}
+			inText = false
+			depth--
+		case xml.CharData:
+			if inText && len(bytes.TrimSpace(t)) > 0 {
+				hasContent = true
+			}
+		}
+	}
+	return images, nil
+}
+
+// 幻灯片中的图片，位置按正文块计数，标题不计入正文
+func pptxImages(pkg *ooxmlPackage) ([]EmbeddedImage, error) {
+	main := pkg.mainPart("ppt/presentation.xml")
+	var doc struct {
+		Ids []struct {
+			Rid string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := pkg.decode(main, &doc); err != nil {
+		return nil, err
+	}
+	slides := map[string]string{}
+	for _, r := range pkg.rels(main) {
+		if strings.HasSuffix(r.Type, relSlide) {
+			slides[r.Id] = r.Target
+		}
+	}
+
+	var images []EmbeddedImage
+	page := 0
+	for _, id := range doc.Ids {
+		part, ok := slides[id.Rid]
+		if !ok {
+			continue
+		}
+		page++
+		items, _, err := readPptxShapes(pkg, part)
+		if err != nil {
+			return nil, err
+		}
+		targets := imageTargets(pkg, part)
+		blocks, titleDone := 0, false
+		for _, item := range items {
+			if item.image != "" {
+				if img, ok := readImage(pkg, targets, item.image); ok {
+					img.Page, img.Block = page, blocks
+					images = append(images, img)
+				}
+				continue
+			}
+			// 与 readPptxSlide 一致，只有第一个标题作为标题
+			if item.isTitle && !titleDone {
+				titleDone = true
+				continue
+			}
+			blocks += len(item.blocks)
+		}
+	}
+	return images, nil
+}
+
+// 工作表绘图中的图片，锚定到左上角单元格，位于工作表的表格之后
+func xlsxImages(pkg *ooxmlPackage) ([]EmbeddedImage, error) {
+	sheets, _, err := workbookSheets(pkg, pkg.mainPart("xl/workbook.xml"))
+	if err != nil {
+		return nil, err
+	}
+	var images []EmbeddedImage
+	for i, sh := range sheets {
+		drawing := pkg.relTarget(sh.part, relDrawing)
+		if drawing == "" {
+			continue
+		}
+		var wsDr struct {
+			Anchors []struct {
+				From struct {
+					Col int `xml:"col"`
+					Row int `xml:"row"`
+				} `xml:"from"`
+				Pics []pptxPicture `xml:"pic"`
+			} `xml:",any"`
+		}
+		if err := pkg.decode(drawing, &wsDr); err != nil {
+			return nil, err
+		}
+		targets := imageTargets(pkg, drawing)
+		for _, a := range wsDr.Anchors {
+			for _, pic := range a.Pics {
+				if img, ok := readImage(pkg, targets, pic.Blip.Embed); ok {
+					img.Page, img.Block = i+1, 1
+					img.Anchor = xlsx.ColIndexToLetters(a.From.Col) + strconv.Itoa(a.From.Row+1)
+					images = append(images, img)
+				}
+			}
+		}
+	}
+	return images, nil
+}
+
+// 识别图片中的文字，单张图片识别失败时跳过，只有 ctx 取消时返回错误
+func recognizeImages(ctx context.Context, images []EmbeddedImage, ocr ImageRecognizer) error {
+	ws, err := workspace.New("")
+	if err != nil {
+		return errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	for i := range images {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !ocrImageTypes[images[i].ContentType] {
+			continue
+		}
+		filePath, err := ws.Save(bytes.NewReader(images[i].Data), strings.TrimPrefix(path.Ext(images[i].Name), "."))
+		if err != nil {
+			continue
+		}
+		if text, _, _, err := ocr.ImageToWord(filePath); err == nil {
+			images[i].Text = strings.TrimSpace(text)
+		}
+	}
+	return nil
+}
+
+// 把图片作为图片块插入到对应页的指定位置
+func insertImages(doc *document.Document, images []EmbeddedImage) {
+	byPage := map[int][]EmbeddedImage{}
+	for _, img := range images {
+		byPage[img.Page] = append(byPage[img.Page], img)
+	}
+	for i := range doc.Pages {
+		list := byPage[doc.Pages[i].Number]
+		if len(list) == 0 {
+			continue
+		}
+		old := doc.Pages[i].Blocks
+		blocks := make([]document.Block, 0, len(old)+len(list))
+		next := 0
+		for k := 0; k <= len(old); k++ {
+			for ; next < len(list) && (list[next].Block <= k || k == len(old)); next++ {
+				blocks = append(blocks, imageBlock(list[next]))
+			}
+			if k < len(old) {
+				blocks = append(blocks, old[k])
+			}
+		}
+		doc.Pages[i].Blocks = blocks
+	}
+}
+
+func imageBlock(img EmbeddedImage) document.Block {
+	return document.Block{
+		Type:  document.BlockImage,
+		Text:  img.Text,
+		Image: &document.Image{Name: img.Name, ContentType: img.ContentType, Anchor: img.Anchor},
+	}
+}
+
+func xmlAttrNs(t xml.StartElement, space string, local string) string {
+	for _, a := range t.Attr {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,55 @@
+package office
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// 按部件名写入 zip 包
+func writeZip(t *testing.T, name string, parts map[string]string) string {
+	t.Helper()
+	name = filepath.Join(t.TempDir(), name)
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	for part, data := range parts {
+		w, _ := zw.Create(part)
+		w.Write([]byte(data))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return name
+}
+
+func TestDocxImageBlock(t *testing.T) {
+	// 只有删除修订的段落和没有行的表格都不是块，图片在第 2 个块之前
+	body := `<w:p><w:del><w:r><w:delText>删除</w:delText></w:r></w:del></w:p>
+<w:tbl><w:tblPr/></w:tbl>
+<w:p><w:r><w:t>A</w:t></w:r></w:p>
+<w:tbl><w:sdt><w:sdtContent><w:tr><w:tc><w:p/></w:tc></w:tr></w:sdtContent></w:sdt></w:tbl>
+<w:p><w:r><w:drawing><a:blip r:embed="rId1"/></w:drawing></w:r></w:p>`
+	name := writeZip(t, "test.docx", map[string]string{
+		"word/document.xml": `<w:document xmlns:w="` + nsWordml + `" xmlns:a="` + nsDrawingml + `" xmlns:r="` + nsRel + `"><w:body>` + body + `</w:body></w:document>`,
+		"word/_rels/document.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/image" Target="media/a.png"/></Relationships>`,
+		"word/media/a.png": "png",
+	})
+	pkg, err := openOoxml(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pkg.Close()
+	images, err := docxImages(pkg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(images) != 1 || images[0].Block != 2 || images[0].Name != "word/media/a.png" {
+		t.Fatalf("images = %+v", images)
+	}
+}
@@ -0,0 +1,485 @@
+package office
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Excel 97-2003 二进制格式（xls，BIFF8）
+//
+// Workbook 流由记录组成：全局子流（工作表列表、共享字符串、格式）之后是各工作表子流，
+// 单元格只读取缓存的值，公式本身不还原。
+
+const (
+	biffFormula    = 0x0006
+	biffEOF        = 0x000a
+	biffDateMode   = 0x0022
+	biffFilePass   = 0x002f
+	biffContinue   = 0x003c
+	biffBoundSheet = 0x0085
+	biffMulRK      = 0x00bd
+	biffRString    = 0x00d6
+	biffXF         = 0x00e0
+	biffMergeCells = 0x00e5
+	biffSST        = 0x00fc
+	biffLabelSST   = 0x00fd
+	biffNumber     = 0x0203
+	biffLabel      = 0x0204
+	biffBoolErr    = 0x0205
+	biffString     = 0x0207
+	biffRK         = 0x027e
+	biffFormat     = 0x041e
+	biffBOF        = 0x0809
+)
+
+// 错误值编码
+var biffErrors = map[byte]string{
+	0x00: "#NULL!", 0x07: "#DIV/0!", 0x0f: "#VALUE!", 0x17: "#REF!",
+	0x1d: "#NAME?", 0x24: "#NUM!", 0x2a: "#N/A",
+}
+
+// 一条记录，data 为记录体，后续的 CONTINUE 记录体依次放在 conts 中
+type biffRecord struct {
+	typ   uint16
+	data  []byte
+	conts [][]byte
+}
+
+type biffSheet struct {
+	name   string
+	offset uint32
+	hidden bool
+}
+
+type biffBook struct {
+	stream   []byte
+	date1904 bool
+	sheets   []biffSheet
+	strings  []string
+	formats  map[int]string
+	xfFormat []int // XF 序号 -> 数字格式编号
+}
+
+func parseLegacyExcel(filePath string, opts ExcelOptions) (*Workbook, error) {
+	streams, err := readOleStreams(filePath, "Workbook", "Book")
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	stream, ok := streams["Workbook"]
+	if !ok {
+		if _, ok := streams["Book"]; ok {
+			return nil, errors.New("不支持 Excel 95 及更早版本的文件！")
+		}
+		return nil, errors.New("不是有效的excel文件！")
+	}
+
+	b := &biffBook{stream: stream, formats: map[int]string{}}
+	if err := b.readGlobals(); err != nil {
+		return nil, err
+	}
+	book := &Workbook{Date1904: b.date1904}
+	for _, sh := range b.sheets {
+		grid, merges := b.readSheet(sh.offset)
+		if opts.FillMergedDown || opts.FillMergedAcross {
+			fillMerged(grid, merges, opts)
+		}
+		book.Sheets = append(book.Sheets, gridSheet(sh.name, sh.hidden, grid, opts, b.date1904))
+	}
+	return book, nil
+}
+
+// 从 offset 开始读取一条记录（含后续的 CONTINUE），返回下一条记录的位置
+func (b *biffBook) record(offset int) (biffRecord, int, bool) {
+	var rec biffRecord
+	if offset+4 > len(b.stream) {
+		return rec, offset, false
+	}
+	rec.typ = binary.LittleEndian.Uint16(b.stream[offset:])
+	size := int(binary.LittleEndian.Uint16(b.stream[offset+2:]))
+	offset += 4
+	if offset+size > len(b.stream) {
+		return rec, offset, false
+	}
+	rec.data = b.stream[offset : offset+size]
+	offset += size
+	for offset+4 <= len(b.stream) && binary.LittleEndian.Uint16(b.stream[offset:]) == biffContinue {
+		size := int(binary.LittleEndian.Uint16(b.stream[offset+2:]))
+		if offset+4+size > len(b.stream) {
+			break
+		}
+		rec.conts = append(rec.conts, b.stream[offset+4:offset+4+size])
+		offset += 4 + size
+	}
+	return rec, offset, true
+}
+
+// 全局子流：到第一个 EOF 为止
+func (b *biffBook) readGlobals() error {
+	rec, offset, ok := b.record(0)
+	if !ok || rec.typ != biffBOF || len(rec.data) < 2 || binary.LittleEndian.Uint16(rec.data) != 0x0600 {
+		return errors.New("不支持的excel文件版本！")
+	}
+	for ok {
+		rec, offset, ok = b.record(offset)
+		if !ok || rec.typ == biffEOF {
+			break
+		}
+		d := rec.data
+		switch rec.typ {
+		case biffFilePass:
+			return errOleEncrypted
+		case biffDateMode:
+			b.date1904 = len(d) >= 2 && binary.LittleEndian.Uint16(d) == 1
+		case biffBoundSheet:
+			// 只读取普通工作表，跳过图表和宏表
+			if len(d) < 8 || d[5] != 0 {
+				continue
+			}
+			name, _ := biffString8(d[6:], 1)
+			b.sheets = append(b.sheets, biffSheet{
+				name:   name,
+				offset: binary.LittleEndian.Uint32(d),
+				hidden: d[4]&0x03 != 0,
+			})
+		case biffFormat:
+			if len(d) < 2 {
+				continue
+			}
+			code, _ := biffString8(d[2:], 2)
+			b.formats[int(binary.LittleEndian.Uint16(d))] = code
+		case biffXF:
+			if len(d) >= 4 {
+				b.xfFormat = append(b.xfFormat, int(binary.LittleEndian.Uint16(d[2:])))
+			}
+		case biffSST:
+			b.strings = readSST(rec)
+		}
+	}
+	return nil
+}
+
+func (b *biffBook) numFmt(xf int) string {
+	if xf < 0 || xf >= len(b.xfFormat) {
+		return ""
+	}
+	id := b.xfFormat[xf]
+	if code, ok := b.formats[id]; ok {
+		return code
+	}
+	return builtinNumFmts[id]
+}
+
+// BIFF8 工作表最多 256 列；行列号来自文件，按单元格总数限制展开的网格
+const (
+	biffMaxCols  = 256
+	biffMaxCells = 1000000
+)
+
+// 读取工作表子流中的单元格和合并区域
+func (b *biffBook) readSheet(offset uint32) ([][]Cell, []mergeRange) {
+	var (
+		grid   [][]Cell
+		merges []mergeRange
+		cells  int
+	)
+	set := func(row, col int, c Cell) {
+		if col >= biffMaxCols {
+			return
+		}
+		for len(grid) <= row {
+			grid = append(grid, nil)
+		}
+		if grow := col + 1 - len(grid[row]); grow > 0 {
+			if cells+grow > biffMaxCells {
+				return
+			}
+			cells += grow
+		}
+		for len(grid[row]) <= col {
+			grid[row] = append(grid[row], Cell{Type: CellTypeEmpty})
+		}
+		grid[row][col] = c
+	}
+
+	rec, next, ok := b.record(int(offset))
+	if !ok || rec.typ != biffBOF {
+		return nil, nil
+	}
+	depth := 1
+	// 字符串公式的结果在紧随其后的 STRING 记录中
+	pendingRow, pendingCol := -1, -1
+	for ok && depth > 0 {
+		rec, next, ok = b.record(next)
+		if !ok {
+			break
+		}
+		d := rec.data
+		switch rec.typ {
+		case biffBOF:
+			// 内嵌的图表等子流
+			depth++
+			continue
+		case biffEOF:
+			depth--
+			continue
+		}
+		if depth > 1 {
+			continue
+		}
+		if rec.typ == biffString {
+			if pendingRow >= 0 {
+				text, _ := biffString8(d, 2)
+				set(pendingRow, pendingCol, stringCell(text))
+				pendingRow, pendingCol = -1, -1
+			}
+			continue
+		}
+		if len(d) < 6 {
+			continue
+		}
+		row := int(binary.LittleEndian.Uint16(d))
+		col := int(binary.LittleEndian.Uint16(d[2:]))
+		xf := int(binary.LittleEndian.Uint16(d[4:]))
+		switch rec.typ {
+		case biffNumber:
+			if len(d) >= 14 {
+				v := math.Float64frombits(binary.LittleEndian.Uint64(d[6:]))
+				set(row, col, numberCell(formatFloat(v), b.numFmt(xf), b.date1904))
+			}
+		case biffRK:
+			if len(d) >= 10 {
+				set(row, col, numberCell(formatFloat(rkValue(binary.LittleEndian.Uint32(d[6:]))), b.numFmt(xf), b.date1904))
+			}
+		case biffMulRK:
+			// 每项 6 字节（XF + RK），末尾 2 字节是最后一列
+			for i := 0; 4+i*6+6 <= len(d)-2; i++ {
+				p := 4 + i*6
+				cellXf := int(binary.LittleEndian.Uint16(d[p:]))
+				v := rkValue(binary.LittleEndian.Uint32(d[p+2:]))
+				set(row, col+i, numberCell(formatFloat(v), b.numFmt(cellXf), b.date1904))
+			}
+		case biffLabelSST:
+			if len(d) >= 10 {
+				i := int(binary.LittleEndian.Uint32(d[6:]))
+				if i < len(b.strings) {
+					set(row, col, stringCell(b.strings[i]))
+				}
+			}
+		case biffLabel, biffRString:
+			text, _ := biffString8(d[6:], 2)
+			set(row, col, stringCell(text))
+		case biffBoolErr:
+			if len(d) >= 8 {
+				if d[7] == 0 {
+					set(row, col, boolCell(d[6] != 0))
+				} else {
+					set(row, col, Cell{Type: CellTypeError, Text: biffErrors[d[6]], Value: biffErrors[d[6]]})
+				}
+			}
+		case biffFormula:
+			if len(d) < 14 {
+				continue
+			}
+			// 结果最后两字节为 0xFFFF 时不是数字，首字节表示类型
+			if d[12] != 0xff || d[13] != 0xff {
+				v := math.Float64frombits(binary.LittleEndian.Uint64(d[6:]))
+				set(row, col, numberCell(formatFloat(v), b.numFmt(xf), b.date1904))
+				continue
+			}
+			switch d[6] {
+			case 0:
+				pendingRow, pendingCol = row, col
+			case 1:
+				set(row, col, boolCell(d[8] != 0))
+			case 2:
+				set(row, col, Cell{Type: CellTypeError, Text: biffErrors[d[8]], Value: biffErrors[d[8]]})
+			}
+		case biffMergeCells:
+			n := int(binary.LittleEndian.Uint16(d))
+			for i := 0; i < n && 2+i*8+8 <= len(d); i++ {
+				p := 2 + i*8
+				merges = append(merges, mergeRange{
+					row:     int(binary.LittleEndian.Uint16(d[p:])),
+					lastRow: int(binary.LittleEndian.Uint16(d[p+2:])),
+					col:     int(binary.LittleEndian.Uint16(d[p+4:])),
+					lastCol: int(binary.LittleEndian.Uint16(d[p+6:])),
+				})
+			}
+		}
+	}
+
+	// 合并区域按同样的上限裁剪，填充后不超过单元格总数
+	kept := merges[:0]
+	for _, m := range merges {
+		if m.row >= len(grid) || m.col >= biffMaxCols || m.row > m.lastRow || m.col > m.lastCol {
+			continue
+		}
+		m.lastRow = minInt(m.lastRow, len(grid)-1)
+		m.lastCol = minInt(m.lastCol, biffMaxCols-1)
+		area := (m.lastRow - m.row + 1) * (m.lastCol - m.col + 1)
+		if cells+area > biffMaxCells {
+			continue
+		}
+		cells += area
+		kept = append(kept, m)
+	}
+	return grid, kept
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// RK 数值：bit1 为整数标志，bit0 表示除以 100
+func rkValue(rk uint32) float64 {
+	var v float64
+	if rk&0x02 != 0 {
+		v = float64(int32(rk) >> 2)
+	} else {
+		v = math.Float64frombits(uint64(rk&0xfffffffc) << 32)
+	}
+	if rk&0x01 != 0 {
+		v /= 100
+	}
+	return v
+}
+
+// 读取 XLUnicodeString，lenSize 为长度字段的字节数（1 或 2），返回字符串和占用的字节数
+func biffString8(d []byte, lenSize int) (string, int) {
+	if len(d) < lenSize+1 {
+		return "", len(d)
+	}
+	n := int(d[0])
+	if lenSize == 2 {
+		n = int(binary.LittleEndian.Uint16(d))
+	}
+	high := d[lenSize]&0x01 != 0
+	pos := lenSize + 1
+	text, used := biffChars(d[pos:], n, high)
+	return text, pos + used
+}
+
+// n 个字符，high 为 true 时每个字符 2 字节（UTF-16LE），否则 1 字节（Latin-1）
+func biffChars(d []byte, n int, high bool) (string, int) {
+	if high {
+		if n*2 > len(d) {
+			n = len(d) / 2
+		}
+		u := make([]uint16, n)
+		for i := range u {
+			u[i] = binary.LittleEndian.Uint16(d[i*2:])
+		}
+		return string(utf16.Decode(u)), n * 2
+	}
+	if n > len(d) {
+		n = len(d)
+	}
+	s, _ := charmap.ISO8859_1.NewDecoder().Bytes(d[:n])
+	return string(s), n
+}
+
+// 共享字符串表，字符串可能跨越 CONTINUE 记录，
+// 字符跨越时新记录以一个标志字节开头，重新指明字符宽度
+func readSST(rec biffRecord) []string {
+	r := &biffSegments{segs: append([][]byte{rec.data}, rec.conts...)}
+	r.skip(4) // cstTotal
+	unique := int(r.uint32())
+	// 字符串数来自文件，每个字符串至少 3 字节，按记录长度限制预分配
+	list := make([]string, 0, minInt(unique, len(rec.data)/3))
+	for i := 0; i < unique && !r.eof(); i++ {
+		n := int(r.uint16())
+		flags := r.byte()
+		runs, ext := 0, 0
+		if flags&0x08 != 0 {
+			runs = int(r.uint16())
+		}
+		if flags&0x04 != 0 {
+			ext = int(r.uint32())
+		}
+		list = append(list, r.chars(n, flags&0x01 != 0))
+		r.skip(runs*4 + ext)
+	}
+	return list
+}
+
+// 跨多个记录体的顺序读取
+type biffSegments struct {
+	segs [][]byte
+	i    int
+	pos  int
+}
+
+func (r *biffSegments) eof() bool {
+	for r.i < len(r.segs) && r.pos >= len(r.segs[r.i]) {
+		r.i++
+		r.pos = 0
+	}
+	return r.i >= len(r.segs)
+}
+
+func (r *biffSegments) byte() byte {
+	if r.eof() {
+		return 0
+	}
+	b := r.segs[r.i][r.pos]
+	r.pos++
+	return b
+}
+
+func (r *biffSegments) uint16() uint16 {
+	return uint16(r.byte()) | uint16(r.byte())<<8
+}
+
+func (r *biffSegments) uint32() uint32 {
+	return uint32(r.uint16()) | uint32(r.uint16())<<16
+}
+
+func (r *biffSegments) skip(n int) {
+	for n > 0 && !r.eof() {
+		step := len(r.segs[r.i]) - r.pos
+		if step > n {
+			step = n
+		}
+		r.pos += step
+		n -= step
+	}
+}
+
+func (r *biffSegments) chars(n int, high bool) string {
+	var out []byte
+	for n > 0 && !r.eof() {
+		seg := r.segs[r.i][r.pos:]
+		width := 1
+		if high {
+			width = 2
+		}
+		take := len(seg) / width
+		if take > n {
+			take = n
+		}
+		if take == 0 {
+			// 记录末尾只剩半个双字节字符，文件已损坏
+			r.pos = len(r.segs[r.i])
+			break
+		}
+		text, used := biffChars(seg, take, high)
+		out = append(out, text...)
+		r.pos += used
+		n -= take
+		// 字符未读完但记录结束，下一记录的首字节重新给出字符宽度
+		if n > 0 && r.pos >= len(r.segs[r.i]) {
+			r.i++
+			r.pos = 0
+			if r.eof() {
+				break
+			}
+			high = r.byte()&0x01 != 0
+		}
+	}
+	return string(out)
+}
@@ -0,0 +1,134 @@
+package office
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// SST 记录体：cstTotal、cstUnique，然后是各字符串
+func sstData(unique uint32, body ...byte) []byte {
+	d := []byte{0, 0, 0, 0, byte(unique), byte(unique >> 8), byte(unique >> 16), byte(unique >> 24)}
+	return append(d, body...)
+}
+
+func TestReadSST(t *testing.T) {
+	// "ab" 为单字节，"中" 为双字节
+	rec := biffRecord{data: sstData(2, 2, 0, 0, 'a', 'b', 1, 0, 1, 0x2d, 0x4e)}
+	got := readSST(rec)
+	if len(got) != 2 || got[0] != "ab" || got[1] != "中" {
+		t.Fatalf("readSST = %q", got)
+	}
+}
+
+func TestReadSSTContinue(t *testing.T) {
+	// 字符串跨越 CONTINUE 记录，新记录的标志字节把单字节改为双字节
+	rec := biffRecord{
+		data:  sstData(1, 3, 0, 0, 'a'),
+		conts: [][]byte{{1, 'b', 0, 0x2d, 0x4e}},
+	}
+	got := readSST(rec)
+	if len(got) != 1 || got[0] != "ab中" {
+		t.Fatalf("readSST = %q", got)
+	}
+}
+
+func TestReadSSTOddByte(t *testing.T) {
+	// 双字节字符串在记录末尾只剩一个字节
+	rec := biffRecord{
+		data:  sstData(1, 2, 0, 1, 'a', 0, 'b'),
+		conts: [][]byte{{1, 'c', 0}},
+	}
+	done := make(chan []string, 1)
+	go func() { done <- readSST(rec) }()
+	select {
+	case got := <-done:
+		if len(got) != 1 || got[0] != "a" {
+			t.Fatalf("readSST = %q", got)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("readSST 没有结束")
+	}
+}
+
+func TestReadSSTHugeCount(t *testing.T) {
+	// cstUnique 为 0xffffffff 时不应按其预分配
+	got := readSST(biffRecord{data: sstData(0xffffffff, 1, 0, 0, 'a')})
+	if len(got) != 1 || got[0] != "a" {
+		t.Fatalf("readSST = %q", got)
+	}
+	if cap(got) > 16 {
+		t.Fatalf("cap = %d", cap(got))
+	}
+}
+
+func TestRkValue(t *testing.T) {
+	cases := []struct {
+		rk   uint32
+		want float64
+	}{
+		{0x3ff00000, 1},      // IEEE 高 30 位
+		{0x3ff00001, 0.01},   // 除以 100
+		{2<<2 | 2, 2},        // 整数
+		{1234<<2 | 3, 12.34}, // 整数除以 100
+	}
+	for _, c := range cases {
+		if got := rkValue(c.rk); got != c.want {
+			t.Errorf("rkValue(%#x) = %v, want %v", c.rk, got, c.want)
+		}
+	}
+}
+
+// 工作表子流：BOF、各记录、EOF
+func biffSheetStream(recs ...[]byte) []byte {
+	b := biffRec(biffBOF, make([]byte, 16))
+	for _, r := range recs {
+		b = append(b, r...)
+	}
+	return append(b, biffRec(biffEOF, nil)...)
+}
+
+func biffRec(typ uint16, data []byte) []byte {
+	b := make([]byte, 4, 4+len(data))
+	binary.LittleEndian.PutUint16(b, typ)
+	binary.LittleEndian.PutUint16(b[2:], uint16(len(data)))
+	return append(b, data...)
+}
+
+// 整数 1 的 RK 记录
+func biffRkRec(row, col int) []byte {
+	d := make([]byte, 10)
+	binary.LittleEndian.PutUint16(d, uint16(row))
+	binary.LittleEndian.PutUint16(d[2:], uint16(col))
+	binary.LittleEndian.PutUint32(d[6:], 1<<2|2)
+	return biffRec(biffRK, d)
+}
+
+func TestReadSheetLimits(t *testing.T) {
+	// 超出 256 列的单元格忽略
+	b := &biffBook{stream: biffSheetStream(biffRkRec(0, 255), biffRkRec(1, 300))}
+	grid, _ := b.readSheet(0)
+	if len(grid) != 1 || len(grid[0]) != 256 || grid[0][255].Text != "1" {
+		t.Fatalf("grid = %d 行", len(grid))
+	}
+
+	// 每行最后一列都有值，再加覆盖整个工作表的合并区域
+	var recs [][]byte
+	for row := 0; row < 65536; row++ {
+		recs = append(recs, biffRkRec(row, 255))
+	}
+	merge := make([]byte, 10)
+	binary.LittleEndian.PutUint16(merge, 1)
+	binary.LittleEndian.PutUint16(merge[4:], 0xffff)
+	binary.LittleEndian.PutUint16(merge[8:], 0xffff)
+	recs = append(recs, biffRec(biffMergeCells, merge))
+	b = &biffBook{stream: biffSheetStream(recs...)}
+	grid, merges := b.readSheet(0)
+	cells := 0
+	for _, row := range grid {
+		cells += len(row)
+	}
+	if cells > biffMaxCells || len(merges) != 0 {
+		t.Fatalf("cells = %d, merges = %v", cells, merges)
+	}
+}
@@ -0,0 +1,327 @@
+package office
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// PowerPoint 97-2003 二进制格式（ppt）
+//
+// Current User 流给出最近一次保存的 UserEditAtom，沿编辑链合并持久化目录（persist id -> 偏移），
+// 再由文档容器中的 SlideListWithText 得到幻灯片顺序。占位符文字保存在 SlideListWithText 中，
+// 其余文本框的文字保存在各幻灯片容器的绘图数据里。
+
+const (
+	pptDocument          = 0x03e8
+	pptSlide             = 0x03ee
+	pptSlideAtom         = 0x03ef
+	pptNotes             = 0x03f0
+	pptSlidePersistAtom  = 0x03f3
+	pptSlideShowInfoAtom = 0x03f9
+	pptTextHeaderAtom    = 0x0f9f
+	pptTextCharsAtom     = 0x0fa0
+	pptTextBytesAtom     = 0x0fa8
+	pptSlideListWithText = 0x0ff0
+	pptUserEditAtom      = 0x0ff5
+	pptPersistDirectory  = 0x1772
+)
+
+// TextHeaderAtom 的文本类型
+const (
+	pptTextTitle       = 0
+	pptTextNotes       = 2
+	pptTextCenterTitle = 6
+)
+
+// 旧版幻灯片的文字
+type legacySlide struct {
+	title  string
+	body   []string
+	notes  []string
+	hidden bool
+}
+
+type pptRecord struct {
+	container bool
+	instance  uint16
+	typ       uint16
+	data      []byte
+}
+
+// 一段文字及其占位符类型
+type pptText struct {
+	kind uint32
+	text string
+}
+
+func parseLegacyPpt(filePath string) ([]legacySlide, error) {
+	streams, err := readOleStreams(filePath, "PowerPoint Document", "Current User")
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	doc, ok := streams["PowerPoint Document"]
+	if !ok {
+		return nil, errors.New("不是有效的ppt文件！")
+	}
+	slides, err := pptSlides(doc, streams["Current User"])
+	if err != nil || len(slides) == 0 {
+		// 编辑链损坏时按流中出现的顺序读取幻灯片
+		return pptScanSlides(doc), nil
+	}
+	return slides, nil
+}
+
+// 读取 offset 处的一条记录
+func pptRecordAt(b []byte, offset int) (pptRecord, int, bool) {
+	if offset < 0 || offset+8 > len(b) {
+		return pptRecord{}, offset, false
+	}
+	verInst := binary.LittleEndian.Uint16(b[offset:])
+	rec := pptRecord{
+		container: verInst&0x0f == 0x0f,
+		instance:  verInst >> 4,
+		typ:       binary.LittleEndian.Uint16(b[offset+2:]),
+	}
+	size := int(binary.LittleEndian.Uint32(b[offset+4:]))
+	end := offset + 8 + size
+	if size < 0 || end > len(b) {
+		end = len(b)
+	}
+	rec.data = b[offset+8 : end]
+	return rec, end, true
+}
+
+// 容器的直接子记录
+func pptChildren(data []byte) []pptRecord {
+	var list []pptRecord
+	for offset := 0; ; {
+		rec, next, ok := pptRecordAt(data, offset)
+		if !ok {
+			return list
+		}
+		list = append(list, rec)
+		offset = next
+	}
+}
+
+// 按编辑链合并持久化目录，新的编辑覆盖旧的
+func pptSlides(doc []byte, currentUser []byte) ([]legacySlide, error) {
+	bad := errors.New("ppt文件结构损坏！")
+	if len(currentUser) < 20 {
+		return nil, bad
+	}
+	offset := int(binary.LittleEndian.Uint32(currentUser[16:]))
+	persist := map[uint32]int{}
+	docRef := uint32(0)
+	for seen := map[int]bool{}; offset > 0 && !seen[offset]; {
+		seen[offset] = true
+		edit, _, ok := pptRecordAt(doc, offset)
+		if !ok || edit.typ != pptUserEditAtom || len(edit.data) < 20 {
+			return nil, bad
+		}
+		if docRef == 0 {
+			docRef = binary.LittleEndian.Uint32(edit.data[16:])
+		}
+		dir, _, ok := pptRecordAt(doc, int(binary.LittleEndian.Uint32(edit.data[12:])))
+		if ok && dir.typ == pptPersistDirectory {
+			d := dir.data
+			for p := 0; p+4 <= len(d); {
+				v := binary.LittleEndian.Uint32(d[p:])
+				id, n := v&0xfffff, int(v>>20)
+				p += 4
+				for i := 0; i < n && p+4 <= len(d); i++ {
+					if _, ok := persist[id+uint32(i)]; !ok {
+						persist[id+uint32(i)] = int(binary.LittleEndian.Uint32(d[p:]))
+					}
+					p += 4
+				}
+			}
+		}
+		offset = int(binary.LittleEndian.Uint32(edit.data[8:]))
+	}
+
+	docOffset, ok := persist[docRef]
+	if !ok {
+		return nil, bad
+	}
+	docRec, _, ok := pptRecordAt(doc, docOffset)
+	if !ok || docRec.typ != pptDocument {
+		return nil, bad
+	}
+
+	type slideRef struct {
+		persist uint32
+		texts   []pptText
+	}
+	var refs []*slideRef
+	notesById := map[uint32]uint32{} // 备注页 slideId -> persist id
+	for _, list := range pptChildren(docRec.data) {
+		if list.typ != pptSlideListWithText {
+			continue
+		}
+		var cur *slideRef
+		kind := uint32(0)
+		for _, rec := range pptChildren(list.data) {
+			switch rec.typ {
+			case pptSlidePersistAtom:
+				if len(rec.data) < 16 {
+					continue
+				}
+				ref := binary.LittleEndian.Uint32(rec.data)
+				switch list.instance {
+				case 0:
+					cur = &slideRef{persist: ref}
+					refs = append(refs, cur)
+				case 2:
+					notesById[binary.LittleEndian.Uint32(rec.data[12:])] = ref
+				}
+			case pptTextHeaderAtom:
+				if len(rec.data) >= 4 {
+					kind = binary.LittleEndian.Uint32(rec.data)
+				}
+			case pptTextCharsAtom, pptTextBytesAtom:
+				if cur != nil && list.instance == 0 {
+					cur.texts = append(cur.texts, pptText{kind: kind, text: pptAtomText(rec)})
+				}
+			}
+		}
+	}
+
+	slides := make([]legacySlide, 0, len(refs))
+	for _, ref := range refs {
+		rec, _, ok := pptRecordAt(doc, persist[ref.persist])
+		if !ok || rec.typ != pptSlide {
+			continue
+		}
+		texts := ref.texts
+		var notesId uint32
+		hidden := false
+		pptWalk(rec.data, func(r pptRecord, kind uint32) {
+			switch r.typ {
+			case pptSlideAtom:
+				if len(r.data) >= 20 {
+					notesId = binary.LittleEndian.Uint32(r.data[16:])
+				}
+			case pptSlideShowInfoAtom:
+				if len(r.data) >= 12 {
+					hidden = binary.LittleEndian.Uint16(r.data[10:])&0x0004 != 0
+				}
+			case pptTextCharsAtom, pptTextBytesAtom:
+				texts = append(texts, pptText{kind: kind, text: pptAtomText(r)})
+			}
+		})
+		s := newLegacySlide(texts)
+		s.hidden = hidden
+		if notesRef, ok := notesById[notesId]; ok && notesId != 0 {
+			if notes, _, ok := pptRecordAt(doc, persist[notesRef]); ok && notes.typ == pptNotes {
+				s.notes = pptNotesText(notes.data)
+			}
+		}
+		slides = append(slides, s)
+	}
+	return slides, nil
+}
+
+// 递归遍历容器，fn 收到每条原子记录及其前面最近的 TextHeaderAtom 类型
+func pptWalk(data []byte, fn func(r pptRecord, kind uint32)) {
+	kind := uint32(4)
+	var walk func(b []byte)
+	walk = func(b []byte) {
+		for _, r := range pptChildren(b) {
+			if r.container {
+				walk(r.data)
+				continue
+			}
+			if r.typ == pptTextHeaderAtom && len(r.data) >= 4 {
+				kind = binary.LittleEndian.Uint32(r.data)
+			}
+			fn(r, kind)
+		}
+	}
+	walk(data)
+}
+
+// 备注页只取备注正文，没有时取全部文字
+func pptNotesText(data []byte) []string {
+	var notes, all []string
+	pptWalk(data, func(r pptRecord, kind uint32) {
+		if r.typ != pptTextCharsAtom && r.typ != pptTextBytesAtom {
+			return
+		}
+		lines := pptParagraphs(pptAtomText(r))
+		all = append(all, lines...)
+		if kind == pptTextNotes {
+			notes = append(notes, lines...)
+		}
+	})
+	if len(notes) > 0 {
+		return notes
+	}
+	return all
+}
+
+// 第一个标题类型的文字作为标题，其余按段落作为正文，重复的文字只保留一次
+func newLegacySlide(texts []pptText) legacySlide {
+	var s legacySlide
+	seen := map[string]bool{}
+	for _, t := range texts {
+		text := strings.TrimSpace(t.text)
+		if text == "" || seen[text] {
+			continue
+		}
+		seen[text] = true
+		if s.title == "" && (t.kind == pptTextTitle || t.kind == pptTextCenterTitle) {
+			s.title = strings.Join(pptParagraphs(text), " ")
+			continue
+		}
+		s.body = append(s.body, pptParagraphs(text)...)
+	}
+	return s
+}
+
+// 没有可用的编辑链时，按出现顺序读取流中的幻灯片容器
+func pptScanSlides(doc []byte) []legacySlide {
+	var slides []legacySlide
+	for _, rec := range pptChildren(doc) {
+		if rec.typ != pptSlide {
+			continue
+		}
+		var texts []pptText
+		pptWalk(rec.data, func(r pptRecord, kind uint32) {
+			if r.typ == pptTextCharsAtom || r.typ == pptTextBytesAtom {
+				texts = append(texts, pptText{kind: kind, text: pptAtomText(r)})
+			}
+		})
+		slides = append(slides, newLegacySlide(texts))
+	}
+	return slides
+}
+
+// TextCharsAtom 为 UTF-16LE，TextBytesAtom 为每字符一字节
+func pptAtomText(r pptRecord) string {
+	if r.typ == pptTextBytesAtom {
+		s, _ := charmap.ISO8859_1.NewDecoder().Bytes(r.data)
+		return string(s)
+	}
+	u := make([]uint16, len(r.data)/2)
+	for i := range u {
+		u[i] = binary.LittleEndian.Uint16(r.data[i*2:])
+	}
+	return string(utf16.Decode(u))
+}
+
+// 段落以 \r 分隔，\v 为段内换行
+func pptParagraphs(text string) []string {
+	var list []string
+	for _, p := range strings.Split(text, "\r") {
+		p = strings.TrimSpace(strings.ReplaceAll(p, "\v", "\n"))
+		if p != "" {
+			list = append(list, p)
+		}
+	}
+	return list
+}
@@ -0,0 +1,114 @@
+package office
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// 一条记录，容器的 verInst 低 4 位为 0xf
+func pptRec(verInst uint16, typ uint16, data ...byte) []byte {
+	b := make([]byte, 8, 8+len(data))
+	binary.LittleEndian.PutUint16(b, verInst)
+	binary.LittleEndian.PutUint16(b[2:], typ)
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(data)))
+	return append(b, data...)
+}
+
+func le32(vs ...uint32) []byte {
+	b := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint32(b[i*4:], v)
+	}
+	return b
+}
+
+func utf16Bytes(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func concat(parts ...[]byte) []byte {
+	var b []byte
+	for _, p := range parts {
+		b = append(b, p...)
+	}
+	return b
+}
+
+// 一张幻灯片的 PowerPoint Document 流和 Current User 流：
+// 标题在 SlideListWithText 中，正文在幻灯片容器中
+func pptStreams() (doc []byte, currentUser []byte) {
+	slide := pptRec(0x0f, pptSlide, concat(
+		pptRec(0, pptTextHeaderAtom, le32(1)...),
+		pptRec(0, pptTextBytesAtom, []byte("body\rline")...),
+	)...)
+	list := pptRec(0x0f, pptSlideListWithText, concat(
+		pptRec(0, pptSlidePersistAtom, le32(2, 0, 0, 0)...),
+		pptRec(0, pptTextHeaderAtom, le32(pptTextTitle)...),
+		pptRec(0, pptTextCharsAtom, utf16Bytes("标题")...),
+	)...)
+	document := pptRec(0x0f, pptDocument, list...)
+
+	doc = concat(document, slide)
+	// 持久化目录：id 1 起 2 项
+	dirOffset := len(doc)
+	doc = append(doc, pptRec(0, pptPersistDirectory, le32(2<<20|1, 0, uint32(len(document)))...)...)
+	editOffset := len(doc)
+	doc = append(doc, pptRec(0, pptUserEditAtom, le32(0, 0, 0, uint32(dirOffset), 1)...)...)
+	currentUser = append(make([]byte, 16), le32(uint32(editOffset))...)
+	return doc, currentUser
+}
+
+func TestPptSlides(t *testing.T) {
+	doc, currentUser := pptStreams()
+	slides, err := pptSlides(doc, currentUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(slides) != 1 || slides[0].title != "标题" || len(slides[0].body) != 2 || slides[0].body[1] != "line" {
+		t.Fatalf("slides = %+v", slides)
+	}
+	if scanned := pptScanSlides(doc); len(scanned) != 1 || len(scanned[0].body) != 2 {
+		t.Fatalf("scanned = %+v", scanned)
+	}
+}
+
+func TestPptEditCycle(t *testing.T) {
+	// UserEditAtom 的上一次编辑指向自身
+	doc, currentUser := pptStreams()
+	edit := int(binary.LittleEndian.Uint32(currentUser[16:]))
+	binary.LittleEndian.PutUint32(doc[edit+8+8:], uint32(edit))
+	done := make(chan struct{})
+	go func() {
+		pptSlides(doc, currentUser)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("pptSlides 没有结束")
+	}
+}
+
+func TestPptTruncated(t *testing.T) {
+	// 任意位置截断的流都不能越界，记录长度超出时截到流末尾
+	doc, currentUser := pptStreams()
+	for n := 0; n < len(doc); n++ {
+		if slides, err := pptSlides(doc[:n], currentUser); err != nil || len(slides) == 0 {
+			pptScanSlides(doc[:n])
+		}
+	}
+	rec, next, ok := pptRecordAt(pptRec(0, pptTextBytesAtom, 'a', 'b')[:9], 0)
+	if !ok || string(rec.data) != "a" || next != 9 {
+		t.Fatalf("rec = %+v, next = %d", rec, next)
+	}
+	// TextCharsAtom 长度为奇数时忽略最后一个字节
+	if got := pptAtomText(pptRecord{typ: pptTextCharsAtom, data: []byte{'a', 0, 'b'}}); got != "a" {
+		t.Fatalf("text = %q", got)
+	}
+}
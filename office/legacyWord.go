@@ -0,0 +1,450 @@
+package office
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Word 97-2003 二进制格式（doc）
+//
+// 正文字符按 Clx 中的片段表（piece table）从 WordDocument 流读取，
+// 段落属性只读取 PAPX 中的表格、大纲级别和列表标记，字符格式不读取。
+
+const (
+	docIdent = 0xa5ec
+
+	docFibBase   = 32 // FibBase 长度
+	docFcLcbClx  = 33 // FibRgFcLcb97 中 fcClx 的序号
+	docFcLcbPapx = 13 // FibRgFcLcb97 中 fcPlcfBtePapx 的序号
+	docLwCcpText = 3  // FibRgLw97 中 ccpText 的序号
+
+	docFkpSize = 512
+)
+
+// 段落属性中用到的 sprm
+const (
+	sprmPFInTable = 0x2416
+	sprmPFTtp     = 0x2417
+	sprmPOutLvl   = 0x2640
+	sprmPIlvl     = 0x260a
+	sprmPIlfo     = 0x460b
+)
+
+// 一段 FC 范围内的段落属性
+type docPapx struct {
+	fcStart, fcEnd uint32
+	inTable        bool
+	ttp            bool // 表格行结束标记
+	outline        int  // 大纲级别 0-8，-1 为正文
+	ilvl           int
+	ilfo           int
+}
+
+// 正文中的一个字符及其在 WordDocument 流中的位置
+type docChar struct {
+	ch rune
+	fc uint32
+}
+
+func parseLegacyWord(filePath string) (*WordDocument, error) {
+	streams, err := readOleStreams(filePath, "WordDocument", "0Table", "1Table")
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	main := streams["WordDocument"]
+	if len(main) < docFibBase || binary.LittleEndian.Uint16(main) != docIdent {
+		return nil, errors.New("不是有效的word文件！")
+	}
+	flags := binary.LittleEndian.Uint16(main[0x0a:])
+	if flags&0x0100 != 0 {
+		return nil, errOleEncrypted
+	}
+	table := streams["0Table"]
+	if flags&0x0200 != 0 {
+		table = streams["1Table"]
+	}
+
+	fib, err := readDocFib(main)
+	if err != nil {
+		return nil, err
+	}
+	chars, err := docText(main, table, fib)
+	if err != nil {
+		return nil, err
+	}
+	papx := docParagraphProps(main, table, fib)
+	return &WordDocument{Blocks: docBlocks(chars, papx)}, nil
+}
+
+type docFib struct {
+	ccpText uint32
+	fcLcb   []uint32 // fc、lcb 交替排列
+}
+
+func (f docFib) pair(i int) (fc uint32, lcb uint32) {
+	if 2*i+1 >= len(f.fcLcb) {
+		return 0, 0
+	}
+	return f.fcLcb[2*i], f.fcLcb[2*i+1]
+}
+
+// FibBase 之后依次是 csw/fibRgW、cslw/fibRgLw、cbRgFcLcb/fibRgFcLcbBlob
+func readDocFib(main []byte) (docFib, error) {
+	var fib docFib
+	bad := errors.New("word文件头损坏！")
+	pos := docFibBase
+	if pos+2 > len(main) {
+		return fib, bad
+	}
+	pos += 2 + int(binary.LittleEndian.Uint16(main[pos:]))*2
+	if pos+2 > len(main) {
+		return fib, bad
+	}
+	cslw := int(binary.LittleEndian.Uint16(main[pos:]))
+	pos += 2
+	if cslw <= docLwCcpText || pos+cslw*4+2 > len(main) {
+		return fib, bad
+	}
+	fib.ccpText = binary.LittleEndian.Uint32(main[pos+docLwCcpText*4:])
+	pos += cslw * 4
+	n := int(binary.LittleEndian.Uint16(main[pos:]))
+	pos += 2
+	for i := 0; i < n*2 && pos+4 <= len(main); i++ {
+		fib.fcLcb = append(fib.fcLcb, binary.LittleEndian.Uint32(main[pos:]))
+		pos += 4
+	}
+	return fib, nil
+}
+
+// 按片段表取出正文的全部字符
+func docText(main, table []byte, fib docFib) ([]docChar, error) {
+	fc, lcb := fib.pair(docFcLcbClx)
+	if lcb == 0 || int(fc)+int(lcb) > len(table) {
+		return nil, errors.New("word文件缺少片段表！")
+	}
+	clx := table[fc : fc+lcb]
+	// 跳过 Prc，找到 Pcdt
+	pos := 0
+	for pos < len(clx) && clx[pos] == 0x01 {
+		if pos+3 > len(clx) {
+			break
+		}
+		pos += 3 + int(binary.LittleEndian.Uint16(clx[pos+1:]))
+	}
+	if pos+5 > len(clx) || clx[pos] != 0x02 {
+		return nil, errors.New("word文件片段表损坏！")
+	}
+	size := int(binary.LittleEndian.Uint32(clx[pos+1:]))
+	plc := clx[pos+5:]
+	if size < len(plc) {
+		plc = plc[:size]
+	}
+	// PlcPcd：n+1 个 CP，n 个 8 字节的 Pcd
+	n := (len(plc) - 4) / 12
+	var chars []docChar
+	for i := 0; i < n; i++ {
+		cpStart := binary.LittleEndian.Uint32(plc[i*4:])
+		cpEnd := binary.LittleEndian.Uint32(plc[(i+1)*4:])
+		if cpStart >= fib.ccpText {
+			break
+		}
+		if cpEnd > fib.ccpText {
+			cpEnd = fib.ccpText
+		}
+		pcd := plc[(n+1)*4+i*8:]
+		fcValue := binary.LittleEndian.Uint32(pcd[2:])
+		compressed := fcValue&0x40000000 != 0
+		start := fcValue &^ 0x40000000
+		if compressed {
+			start /= 2
+		}
+		for cp := cpStart; cp < cpEnd; cp++ {
+			if compressed {
+				at := start + (cp - cpStart)
+				if int(at) >= len(main) {
+					break
+				}
+				chars = append(chars, docChar{ch: compressedChar(main[at]), fc: at})
+				continue
+			}
+			at := start + (cp-cpStart)*2
+			if int(at)+2 > len(main) {
+				break
+			}
+			chars = append(chars, docChar{ch: rune(binary.LittleEndian.Uint16(main[at:])), fc: at})
+		}
+	}
+	return mergeSurrogates(chars), nil
+}
+
+// 压缩片段按 cp1252 存储，控制字符保持原值
+func compressedChar(b byte) rune {
+	if b < 0x80 {
+		return rune(b)
+	}
+	return charmap.Windows1252.DecodeByte(b)
+}
+
+// UTF-16 代理对合并为一个字符
+func mergeSurrogates(chars []docChar) []docChar {
+	out := chars[:0]
+	for i := 0; i < len(chars); i++ {
+		c := chars[i]
+		if utf16.IsSurrogate(c.ch) && i+1 < len(chars) {
+			if r := utf16.DecodeRune(c.ch, chars[i+1].ch); r != unicode.ReplacementChar {
+				c.ch = r
+				i++
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+// 读取 PlcBtePapx 指向的各个 PAPX FKP 页
+func docParagraphProps(main, table []byte, fib docFib) []docPapx {
+	fc, lcb := fib.pair(docFcLcbPapx)
+	if lcb < 8 || int(fc)+int(lcb) > len(table) {
+		return nil
+	}
+	plc := table[fc : fc+lcb]
+	n := (len(plc) - 4) / 8
+	var list []docPapx
+	for i := 0; i < n; i++ {
+		pn := binary.LittleEndian.Uint32(plc[(n+1)*4+i*4:]) & 0x3fffff
+		page := int(pn) * docFkpSize
+		if page+docFkpSize > len(main) {
+			continue
+		}
+		list = append(list, parsePapxFkp(main[page:page+docFkpSize])...)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].fcStart < list[j].fcStart })
+	return list
+}
+
+func parsePapxFkp(fkp []byte) []docPapx {
+	crun := int(fkp[docFkpSize-1])
+	if (crun+1)*4+crun*13 > docFkpSize-1 {
+		return nil
+	}
+	list := make([]docPapx, 0, crun)
+	for i := 0; i < crun; i++ {
+		p := docPapx{
+			fcStart: binary.LittleEndian.Uint32(fkp[i*4:]),
+			fcEnd:   binary.LittleEndian.Uint32(fkp[(i+1)*4:]),
+			outline: -1,
+		}
+		offset := int(fkp[(crun+1)*4+i*13]) * 2
+		if offset > 0 && offset < docFkpSize-1 {
+			cb := int(fkp[offset]) * 2
+			start := offset + 1
+			if cb == 0 {
+				cb = int(fkp[offset+1]) * 2
+				start++
+			} else {
+				cb--
+			}
+			// 前两个字节是样式序号 istd
+			if start+cb <= docFkpSize-1 && cb >= 2 {
+				p.applySprms(fkp[start+2 : start+cb])
+			}
+		}
+		list = append(list, p)
+	}
+	return list
+}
+
+func (p *docPapx) applySprms(grpprl []byte) {
+	for pos := 0; pos+2 <= len(grpprl); {
+		sprm := binary.LittleEndian.Uint16(grpprl[pos:])
+		pos += 2
+		size := sprmOperandSize(sprm, grpprl[pos:])
+		if size < 0 || pos+size > len(grpprl) {
+			return
+		}
+		operand := grpprl[pos : pos+size]
+		switch sprm {
+		case sprmPFInTable:
+			p.inTable = operand[0] != 0
+		case sprmPFTtp:
+			p.ttp = operand[0] != 0
+		case sprmPOutLvl:
+			if operand[0] < 9 {
+				p.outline = int(operand[0])
+			}
+		case sprmPIlvl:
+			p.ilvl = int(operand[0])
+		case sprmPIlfo:
+			p.ilfo = int(int16(binary.LittleEndian.Uint16(operand)))
+		}
+		pos += size
+	}
+}
+
+// 操作数长度由 sprm 的高 3 位（spra）决定，6 为变长
+func sprmOperandSize(sprm uint16, rest []byte) int {
+	switch sprm >> 13 {
+	case 0, 1:
+		return 1
+	case 2, 4, 5:
+		return 2
+	case 3:
+		return 4
+	case 7:
+		return 3
+	}
+	// sprmTDefTable、sprmPChgTabs 的长度字段特殊
+	if sprm == 0xd608 || sprm == 0xd606 {
+		if len(rest) < 2 {
+			return -1
+		}
+		return int(binary.LittleEndian.Uint16(rest)) + 1
+	}
+	if sprm == 0xc615 {
+		if len(rest) < 1 {
+			return -1
+		}
+		if rest[0] == 255 {
+			return -1
+		}
+		return int(rest[0]) + 1
+	}
+	if len(rest) < 1 {
+		return -1
+	}
+	return int(rest[0]) + 1
+}
+
+func findPapx(list []docPapx, fc uint32) docPapx {
+	i := sort.Search(len(list), func(i int) bool { return list[i].fcEnd > fc })
+	if i < len(list) && list[i].fcStart <= fc {
+		return list[i]
+	}
+	return docPapx{outline: -1}
+}
+
+// 按段落标记（0x0D）和单元格标记（0x07）切分为段落和表格
+func docBlocks(chars []docChar, papx []docPapx) []WordBlock {
+	var (
+		blocks []WordBlock
+		text   strings.Builder
+		cell   []string // 当前单元格中已结束的段落
+		row    []WordCell
+		table  *WordTable
+		fields []bool // 嵌套域，true 表示处于域代码部分
+	)
+	inCode := func() bool {
+		for _, code := range fields {
+			if code {
+				return true
+			}
+		}
+		return false
+	}
+	flushTable := func() {
+		if table != nil {
+			blocks = append(blocks, WordBlock{Table: table})
+			table = nil
+		}
+	}
+	endCell := func() {
+		cell = append(cell, strings.TrimSpace(text.String()))
+		text.Reset()
+		c := WordCell{Text: strings.TrimSpace(strings.Join(cell, "\n")), ColSpan: 1, RowSpan: 1}
+		for _, t := range cell {
+			if t == "" {
+				continue
+			}
+			c.Blocks = append(c.Blocks, WordBlock{Paragraph: &WordParagraph{Text: t, Spans: []WordSpan{{Text: t}}}})
+		}
+		row = append(row, c)
+		cell = nil
+	}
+	endRow := func() {
+		text.Reset()
+		if table == nil {
+			table = &WordTable{}
+		}
+		for i := range row {
+			row[i].Col = i
+		}
+		table.Rows = append(table.Rows, WordRow{Cells: row})
+		if len(row) > table.Cols {
+			table.Cols = len(row)
+		}
+		row = nil
+	}
+	endParagraph := func(p docPapx) {
+		if p.inTable {
+			cell = append(cell, strings.TrimSpace(text.String()))
+			text.Reset()
+			return
+		}
+		flushTable()
+		t := strings.TrimSpace(text.String())
+		text.Reset()
+		if t == "" {
+			return
+		}
+		para := &WordParagraph{Text: t, Spans: []WordSpan{{Text: t}}}
+		if p.outline >= 0 {
+			para.HeadingLevel = p.outline + 1
+		} else if p.ilfo > 0 {
+			para.IsList, para.ListLevel, para.NumId = true, p.ilvl, int64(p.ilfo)
+		}
+		blocks = append(blocks, WordBlock{Paragraph: para})
+	}
+
+	for _, c := range chars {
+		switch c.ch {
+		case 0x13: // 域开始
+			fields = append(fields, true)
+			continue
+		case 0x14: // 域分隔，之后是域结果
+			if len(fields) > 0 {
+				fields[len(fields)-1] = false
+			}
+			continue
+		case 0x15: // 域结束
+			if len(fields) > 0 {
+				fields = fields[:len(fields)-1]
+			}
+			continue
+		}
+		if inCode() {
+			continue
+		}
+		switch c.ch {
+		case 0x0d, 0x0c:
+			endParagraph(findPapx(papx, c.fc))
+		case 0x07:
+			if findPapx(papx, c.fc).ttp {
+				endRow()
+			} else {
+				endCell()
+			}
+		case 0x0b:
+			text.WriteByte('\n')
+		case 0x09:
+			text.WriteByte('\t')
+		case 0x1e:
+			text.WriteByte('-')
+		case 0xa0:
+			text.WriteByte(' ')
+		default:
+			// 图片、脚注引用等对象占位符
+			if c.ch >= 0x20 {
+				text.WriteRune(c.ch)
+			}
+		}
+	}
+	endParagraph(docPapx{outline: -1})
+	flushTable()
+	return blocks
+}
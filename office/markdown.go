@@ -0,0 +1,552 @@
+package office
+
+import (
+	"errors"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// markdown 按行解析块级结构：标题、列表、表格、引用、代码块和段落，
+// 行内的强调转为粗体、斜体片段，链接和图片只保留文字，行内 html 标签去掉。
+
+// markdown文件转结构化内容，编码自动检测
+func MarkdownToStructure(filePath string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return fileToWord(filePath, parseMarkdown)
+}
+
+// markdown地址文件转结构化内容
+func MarkdownUrlToStructure(url string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return urlToWord(url, parseMarkdown)
+}
+
+func parseMarkdown(filePath string) (*WordDocument, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	text, _, err := decodeText(data, "")
+	if err != nil {
+		return nil, err
+	}
+	return &WordDocument{Blocks: markdownBlocks(markdownLines(text))}, nil
+}
+
+var (
+	mdComment     = regexp.MustCompile(`(?s)<!--.*?-->`)
+	mdFrontMatter = regexp.MustCompile(`^[\w-]+\s*:`)
+	mdHeading     = regexp.MustCompile(`^(#{1,6})(?:\s+(.*?))?(?:\s+#+)?\s*$`)
+	mdRule        = regexp.MustCompile(`^([-*_])(?:\s*([-*_]))*$`)
+	mdListItem    = regexp.MustCompile(`^([-*+]|(\d{1,9})([.)]))(?:\s+(.*))?$`)
+	mdTableDelim  = regexp.MustCompile(`^\|?\s*:?-+:?\s*(\|\s*:?-+:?\s*)*\|?$`)
+	mdHtmlTag     = regexp.MustCompile(`^/?([A-Za-z][A-Za-z0-9-]*)(\s[^<>]*)?/?$`)
+	mdAutolink    = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]{1,31}:[^\s<>]*|[^\s<>@]+@[^\s<>@]+)$`)
+)
+
+// 可以用反斜杠转义的字符
+const mdPunct = "\\`*_{}[]()#+-.!|<>~\"'"
+
+func isMarkdownSuffix(suffix string) bool {
+	switch strings.ToLower(suffix) {
+	case "md", "markdown", "mdown", "mkd":
+		return true
+	}
+	return false
+}
+
+// 统一换行，去掉注释和开头的 YAML 元数据
+func markdownLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	text = mdComment.ReplaceAllString(text, "")
+	lines := strings.Split(text, "\n")
+	if len(lines) > 1 && strings.TrimSpace(lines[0]) == "---" && mdFrontMatter.MatchString(lines[1]) {
+		for i := 1; i < len(lines); i++ {
+			if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+				return lines[i+1:]
+			}
+		}
+	}
+	return lines
+}
+
+type mdParser struct {
+	blocks  []WordBlock
+	para    []string      // 当前段落的各行
+	tmpl    WordParagraph // 当前段落的标题、列表属性
+	indents []int         // 各级列表项的缩进
+	counts  []int         // 各级有序列表的当前编号
+}
+
+func markdownBlocks(lines []string) []WordBlock {
+	p := &mdParser{}
+	blank := true
+	for i := 0; i < len(lines); i++ {
+		line := strings.ReplaceAll(lines[i], "\t", "    ")
+		trimmed := strings.TrimSpace(line)
+		indent := len(line) - len(strings.TrimLeft(line, " "))
+		if trimmed == "" {
+			p.flush()
+			blank = true
+			continue
+		}
+		// 列表之后顶格的段落结束列表
+		if blank && indent == 0 && !mdListItem.MatchString(trimmed) {
+			p.endList()
+		}
+
+		switch {
+		case strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~"):
+			p.flush()
+			fence := trimmed[:3]
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), fence); i++ {
+				code = append(code, strings.TrimRight(lines[i], " \t"))
+			}
+			p.code(code)
+		case indent >= 4 && blank && len(p.indents) == 0:
+			// 缩进代码块，到下一个不缩进的非空行为止
+			code := []string{line[4:]}
+			for ; i+1 < len(lines); i++ {
+				next := strings.ReplaceAll(lines[i+1], "\t", "    ")
+				if strings.TrimSpace(next) != "" && !strings.HasPrefix(next, "    ") {
+					break
+				}
+				code = append(code, strings.TrimPrefix(next, "    "))
+			}
+			p.code(code)
+		case indent < 4 && mdHeading.MatchString(trimmed):
+			p.flush()
+			m := mdHeading.FindStringSubmatch(trimmed)
+			p.tmpl = WordParagraph{HeadingLevel: len(m[1])}
+			p.para = []string{m[2]}
+			p.flush()
+		case len(p.para) > 0 && !p.tmpl.IsList && indent < 4 && (strings.Trim(trimmed, "=") == "" || strings.Trim(trimmed, "-") == ""):
+			// 段落下一行的 === 或 --- 把段落变为一、二级标题
+			p.tmpl.HeadingLevel = 1
+			if trimmed[0] == '-' {
+				p.tmpl.HeadingLevel = 2
+			}
+			p.flush()
+		case indent < 4 && len(trimmed) >= 3 && mdRule.MatchString(trimmed) && strings.Count(trimmed, trimmed[:1]) >= 3:
+			p.flush()
+			p.endList()
+		case strings.Contains(trimmed, "|") && i+1 < len(lines) && mdTableDelim.MatchString(strings.TrimSpace(lines[i+1])):
+			p.flush()
+			rows := [][]string{mdTableRow(trimmed)}
+			for i += 2; i < len(lines) && strings.Contains(lines[i], "|"); i++ {
+				rows = append(rows, mdTableRow(strings.TrimSpace(lines[i])))
+			}
+			i--
+			p.blocks = append(p.blocks, WordBlock{Table: mdTable(rows)})
+		case strings.HasPrefix(trimmed, ">"):
+			p.flush()
+			var quote []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimSpace(lines[i]), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimSpace(lines[i]), ">")
+				quote = append(quote, strings.TrimPrefix(q, " "))
+			}
+			i--
+			p.blocks = append(p.blocks, markdownBlocks(quote)...)
+		case mdListItem.MatchString(trimmed):
+			p.flush()
+			p.item(indent, mdListItem.FindStringSubmatch(trimmed))
+		default:
+			p.para = append(p.para, line)
+		}
+		blank = false
+	}
+	p.flush()
+	return p.blocks
+}
+
+// 列表项，层级按缩进确定，有序列表从第一项的编号开始依次递增
+func (p *mdParser) item(indent int, m []string) {
+	for len(p.indents) > 0 && p.indents[len(p.indents)-1] > indent {
+		p.indents = p.indents[:len(p.indents)-1]
+	}
+	if len(p.indents) == 0 || p.indents[len(p.indents)-1] < indent {
+		p.indents = append(p.indents, indent)
+	}
+	level := len(p.indents) - 1
+	for len(p.counts) <= level {
+		p.counts = append(p.counts, 0)
+	}
+	p.counts = p.counts[:level+1]
+
+	p.tmpl = WordParagraph{IsList: true, ListLevel: level, Numbering: "•"}
+	if m[2] != "" {
+		if p.counts[level] == 0 {
+			p.counts[level], _ = strconv.Atoi(m[2])
+		} else {
+			p.counts[level]++
+		}
+		p.tmpl.Ordered = true
+		p.tmpl.Numbering = strconv.Itoa(p.counts[level]) + m[3]
+	} else {
+		p.counts[level] = 0
+	}
+	p.para = []string{m[4]}
+}
+
+func (p *mdParser) endList() {
+	p.indents, p.counts = nil, nil
+}
+
+// 结束当前段落，各行按软换行连接
+func (p *mdParser) flush() {
+	lines := p.para
+	para := p.tmpl
+	p.para, p.tmpl = nil, WordParagraph{}
+	if len(lines) == 0 {
+		return
+	}
+	para.Spans = mergeSpans(mdInline(mdJoin(lines), false, false))
+	for _, s := range para.Spans {
+		para.Text += s.Text
+	}
+	if strings.TrimSpace(para.Text) == "" {
+		return
+	}
+	p.blocks = append(p.blocks, WordBlock{Paragraph: &para})
+}
+
+// 代码块原样作为一个段落
+func (p *mdParser) code(lines []string) {
+	text := strings.Trim(strings.Join(lines, "\n"), "\n")
+	if strings.TrimSpace(text) == "" {
+		return
+	}
+	p.blocks = append(p.blocks, WordBlock{Paragraph: &WordParagraph{Text: text, Spans: []WordSpan{{Text: text}}}})
+}
+
+// 软换行：中文之间直接相连，其他以空格连接；行尾两个空格或反斜杠为硬换行
+func mdJoin(lines []string) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		hard := strings.HasSuffix(line, "  ")
+		line = strings.TrimSpace(line)
+		if strings.HasSuffix(line, "\\") && i < len(lines)-1 {
+			hard = true
+			line = strings.TrimSuffix(line, "\\")
+		}
+		sb.WriteString(line)
+		if i == len(lines)-1 {
+			break
+		}
+		next := strings.TrimSpace(lines[i+1])
+		last, _ := utf8.DecodeLastRuneInString(line)
+		first, _ := utf8.DecodeRuneInString(next)
+		switch {
+		case hard:
+			sb.WriteString("\n")
+		case !(isWideRune(last) && isWideRune(first)):
+			sb.WriteString(" ")
+		}
+	}
+	return sb.String()
+}
+
+// 中日韩文字和全角标点
+func isWideRune(r rune) bool {
+	return r >= 0x2e80 && r != utf8.RuneError
+}
+
+// 表格行按未转义的竖线拆分单元格
+func mdTableRow(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, "\\|") {
+		line = line[:len(line)-1]
+	}
+	var cells []string
+	var sb strings.Builder
+	code := false
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line) && line[i+1] == '|':
+			sb.WriteByte('|')
+			i++
+		case c == '`':
+			code = !code
+			sb.WriteByte(c)
+		case c == '|' && !code:
+			cells = append(cells, strings.TrimSpace(sb.String()))
+			sb.Reset()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(sb.String()))
+}
+
+// 表格列数以表头为准，数据行多出的单元格丢弃
+func mdTable(rows [][]string) *WordTable {
+	t := &WordTable{Cols: len(rows[0])}
+	for _, cells := range rows {
+		row := WordRow{}
+		for j := 0; j < t.Cols; j++ {
+			cell := WordCell{Col: j, ColSpan: 1, RowSpan: 1}
+			if j < len(cells) {
+				spans := mergeSpans(mdInline(cells[j], false, false))
+				for _, s := range spans {
+					cell.Text += s.Text
+				}
+				if strings.TrimSpace(cell.Text) != "" {
+					cell.Blocks = []WordBlock{{Paragraph: &WordParagraph{Text: cell.Text, Spans: spans}}}
+				}
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	return t
+}
+
+// 行内强调、链接等最多嵌套的层数
+const mdMaxDepth = 32
+
+// 行内解析的状态。结束标记是否有效与开始标记无关，某种标记向后找不到结束位置时记下，
+// 后面同样的开始标记不再查找；括号一次配对，使整行的处理为线性时间
+type mdInliner struct {
+	s        string
+	depth    int
+	noCloser [2][4]bool   // 找不到结束位置的 * 和 _ 强调标记，按标记长度
+	noCode   map[int]bool // 找不到结束位置的反引号，按反引号个数
+	noStrike bool
+	noTag    bool
+	brackets map[int]int // 方括号、圆括号的位置 -> 配对的右括号位置
+}
+
+// 行内内容转为文字片段
+func mdInline(s string, bold bool, italic bool) []WordSpan {
+	return (&mdInliner{s: s}).spans(bold, italic)
+}
+
+// 嵌套内容的文字片段
+func (p *mdInliner) inner(s string, bold bool, italic bool) []WordSpan {
+	return (&mdInliner{s: s, depth: p.depth + 1}).spans(bold, italic)
+}
+
+func (p *mdInliner) spans(bold bool, italic bool) []WordSpan {
+	s := p.s
+	if p.depth > mdMaxDepth {
+		return []WordSpan{{Text: html.UnescapeString(s), Bold: bold, Italic: italic}}
+	}
+	var spans []WordSpan
+	var lit strings.Builder
+	emit := func() {
+		if lit.Len() > 0 {
+			spans = append(spans, WordSpan{Text: html.UnescapeString(lit.String()), Bold: bold, Italic: italic})
+			lit.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s) && strings.IndexByte(mdPunct, s[i+1]) >= 0:
+			lit.WriteByte(s[i+1])
+			i += 2
+			continue
+		case c == '`':
+			n := mdRun(s, i)
+			if j := p.codeEnd(i, n); j >= 0 {
+				code := s[i+n : j]
+				if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' {
+					code = code[1 : len(code)-1]
+				}
+				emit()
+				spans = append(spans, WordSpan{Text: code, Bold: bold, Italic: italic})
+				i = j + n
+				continue
+			}
+			lit.WriteString(s[i : i+n])
+			i += n
+			continue
+		case c == '!' && strings.HasPrefix(s[i+1:], "["), c == '[':
+			start := i
+			if c == '!' {
+				start++
+			}
+			// 链接取文字，图片取替代文字
+			if text, end, ok := p.link(start); ok {
+				emit()
+				spans = append(spans, p.inner(text, bold, italic)...)
+				i = end
+				continue
+			}
+		case c == '<':
+			if j := p.tagEnd(i); j > 0 {
+				inner := s[i+1 : j]
+				if mdAutolink.MatchString(inner) {
+					lit.WriteString(inner)
+					i = j + 1
+					continue
+				}
+				if m := mdHtmlTag.FindStringSubmatch(inner); m != nil {
+					if strings.EqualFold(m[1], "br") {
+						lit.WriteString("\n")
+					}
+					i = j + 1
+					continue
+				}
+			}
+		case c == '*' || c == '_':
+			run := mdRun(s, i)
+			n := minInt(run, 3)
+			if end := p.closer(i, n); end >= 0 {
+				emit()
+				spans = append(spans, p.inner(s[i+n:end], bold || n >= 2, italic || n != 2)...)
+				i = end + n
+				continue
+			}
+			lit.WriteString(s[i : i+run])
+			i += run
+			continue
+		case c == '~' && strings.HasPrefix(s[i:], "~~") && !p.noStrike:
+			// 删除线只去掉标记
+			j := strings.Index(s[i+2:], "~~")
+			if j < 0 {
+				p.noStrike = true
+			} else if j > 0 {
+				emit()
+				spans = append(spans, p.inner(s[i+2:i+2+j], bold, italic)...)
+				i += j + 4
+				continue
+			}
+		}
+		lit.WriteByte(c)
+		i++
+	}
+	emit()
+	return spans
+}
+
+// 从 i 开始连续相同字符的个数
+func mdRun(s string, i int) int {
+	n := 1
+	for i+n < len(s) && s[i+n] == s[i] {
+		n++
+	}
+	return n
+}
+
+// 从 i 开始的 n 个反引号之后同样个数反引号的位置，找不到返回 -1
+func (p *mdInliner) codeEnd(i int, n int) int {
+	if p.noCode[n] {
+		return -1
+	}
+	j := strings.Index(p.s[i+n:], p.s[i:i+n])
+	if j < 0 {
+		if p.noCode == nil {
+			p.noCode = map[int]bool{}
+		}
+		p.noCode[n] = true
+		return -1
+	}
+	return i + n + j
+}
+
+// 标签或自动链接的结束位置，标签内不能有 <；找不到返回 -1
+func (p *mdInliner) tagEnd(i int) int {
+	if p.noTag {
+		return -1
+	}
+	j := strings.IndexAny(p.s[i+1:], "<>")
+	switch {
+	case j < 0:
+		p.noTag = true
+		return -1
+	case p.s[i+1+j] == '<':
+		return -1
+	}
+	return i + 1 + j
+}
+
+// 强调标记的结束位置，标记内侧不能是空白，下划线不能在单词中间；找不到返回 -1
+func (p *mdInliner) closer(i int, n int) int {
+	s := p.s
+	c := s[i]
+	if i+n >= len(s) || s[i+n] == ' ' || (c == '_' && i > 0 && isWordByte(s[i-1])) {
+		return -1
+	}
+	kind := 0
+	if c == '_' {
+		kind = 1
+	}
+	if p.noCloser[kind][n] {
+		return -1
+	}
+	marker := s[i : i+n]
+	for j := i + n + 1; j+n <= len(s); j++ {
+		if !strings.HasPrefix(s[j:], marker) || s[j-1] == ' ' || s[j-1] == c || s[j-1] == '\\' {
+			continue
+		}
+		if j+n < len(s) && (s[j+n] == c || (c == '_' && isWordByte(s[j+n]))) {
+			continue
+		}
+		return j
+	}
+	p.noCloser[kind][n] = true
+	return -1
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+}
+
+// [文字](地址) 或 [文字][引用]，返回文字和结束位置
+func (p *mdInliner) link(i int) (string, int, bool) {
+	close := p.match(i)
+	if close < 0 || close+1 >= len(p.s) {
+		return "", 0, false
+	}
+	text := p.s[i+1 : close]
+	if c := p.s[close+1]; c == '(' || c == '[' {
+		if end := p.match(close + 1); end >= 0 {
+			return text, end + 1, true
+		}
+	}
+	return "", 0, false
+}
+
+// 与 i 处左括号配对的右括号位置，找不到返回 -1
+func (p *mdInliner) match(i int) int {
+	if p.brackets == nil {
+		p.brackets = mdBrackets(p.s)
+	}
+	if j, ok := p.brackets[i]; ok {
+		return j
+	}
+	return -1
+}
+
+// 方括号和圆括号分别配对，反斜杠转义的括号不计
+func mdBrackets(s string) map[int]int {
+	pairs := map[int]int{}
+	var square, round []int
+	for j := 0; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			square = append(square, j)
+		case '(':
+			round = append(round, j)
+		case ']':
+			if len(square) > 0 {
+				pairs[square[len(square)-1]] = j
+				square = square[:len(square)-1]
+			}
+		case ')':
+			if len(round) > 0 {
+				pairs[round[len(round)-1]] = j
+				round = round[:len(round)-1]
+			}
+		}
+	}
+	return pairs
+}
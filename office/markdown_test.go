@@ -0,0 +1,97 @@
+package office
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// 片段写成 [文字] 形式，粗体加 b、斜体加 i
+func spansString(spans []WordSpan) string {
+	var sb strings.Builder
+	for _, s := range mergeSpans(spans) {
+		flags := ""
+		if s.Bold {
+			flags += "b"
+		}
+		if s.Italic {
+			flags += "i"
+		}
+		fmt.Fprintf(&sb, "[%s%s]", flags, s.Text)
+	}
+	return sb.String()
+}
+
+func TestMdInline(t *testing.T) {
+	cases := []struct {
+		in   string
+		want string
+	}{
+		{"a **b** c", "[a ][bb][ c]"},
+		{"*a **b** c*", "[ia ][bib][i c]"},
+		{"***x***", "[bix]"},
+		{"snake_case_name", "[snake_case_name]"},
+		{"2 * 3 * 4", "[2 * 3 * 4]"},
+		{"`co*de*` \\*x\\*", "[co*de* *x*]"},
+		{"[链接](http://a.com/x_(y)) ![图](a.png)", "[链接 图]"},
+		{"[a [b](x)", "[[a b]"},
+		{"~~删除~~ <span>去标签</span> &amp; <http://x.com>", "[删除 去标签 & http://x.com]"},
+		{"a <b", "[a <b]"},
+		{"**a", "[**a]"},
+	}
+	for _, c := range cases {
+		if got := spansString(mdInline(c.in, false, false)); got != c.want {
+			t.Errorf("mdInline(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+}
+
+func TestMdInlineLongLine(t *testing.T) {
+	// 没有结束位置的标记不能使处理时间随行长平方增长
+	for _, unit := range []string{"*a ", "_a ", "**a ", "`a ", "[a ", "(a ", "<a ", "~~a ", "*", "[", "![a]"} {
+		line := strings.Repeat(unit, 200000/len(unit))
+		start := time.Now()
+		mdInline(line, false, false)
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("%q x %d: %v", unit, 200000/len(unit), d)
+		}
+	}
+}
+
+func TestMdInlineDeepNesting(t *testing.T) {
+	line := strings.Repeat("[", 50000) + "x" + strings.Repeat("](a)", 50000)
+	start := time.Now()
+	if got := spansString(mdInline(line, false, false)); !strings.Contains(got, "x") {
+		t.Fatalf("text lost: %.40s", got)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("took %v", d)
+	}
+}
+
+func TestMarkdownBlocks(t *testing.T) {
+	src := "# 标题 #\n\n第一行\n第二行 and\nmore\n\n- 项目\n  - 子项\n\n3. 三\n4. 四\n\n| a | b |\n|---|---|\n| 1 \\| 2 | 3 |\n"
+	blocks := markdownBlocks(markdownLines(src))
+	var got []string
+	for _, b := range blocks {
+		if b.Table != nil {
+			got = append(got, "table:"+strings.ReplaceAll(b.Table.Text(), "\t", ","))
+			continue
+		}
+		p := b.Paragraph
+		got = append(got, fmt.Sprintf("%d/%d/%s/%s", p.HeadingLevel, p.ListLevel, p.Numbering, p.Text))
+	}
+	want := []string{
+		"1/0//标题",
+		"0/0//第一行第二行 and more",
+		"0/0/•/项目",
+		"0/1/•/子项",
+		"0/0/3./三",
+		"0/0/4./四",
+		"table:a,b\n1 | 2,3",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("blocks =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
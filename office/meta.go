@@ -0,0 +1,397 @@
+package office
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/richardlehane/msoleps"
+	"github.com/richardlehane/msoleps/types"
+
+	"github.com/bangongyi/toolkits/document"
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// 文档属性的来源：OOXML 为 docProps/core.xml 和 app.xml，
+// doc/xls/ppt 为 SummaryInformation 和 DocumentSummaryInformation 流，ODF 为 meta.xml
+
+const (
+	relCoreProperties     = "/metadata/core-properties"
+	relExtendedProperties = "/extended-properties"
+
+	// mscfb 返回的流名已去掉开头的 \x05
+	oleSummary         = "SummaryInformation"
+	oleDocumentSummary = "DocumentSummaryInformation"
+)
+
+// 读取文件的文档属性，格式按文件头识别
+func FileToMeta(filePath string) (meta *document.Meta, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	meta, err = fileMeta(filePath, suffix)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return meta, suffix, size, nil
+}
+
+// 读取地址文件的文档属性
+func FileUrlToMeta(url string) (meta *document.Meta, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return nil, "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	meta, err = fileMeta(filePath, suffix)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return meta, suffix, size, nil
+}
+
+func fileMeta(filePath string, suffix string) (*document.Meta, error) {
+	format, err := detectFormat(filePath, strings.ToLower(suffix))
+	if err != nil {
+		return nil, err
+	}
+	meta, err := readMeta(filePath, format)
+	if err != nil {
+		return nil, errors.New("读取文档属性失败！")
+	}
+	return &meta, nil
+}
+
+// 按格式读取文档属性，没有属性信息的格式返回空值
+func readMeta(filePath string, format string) (document.Meta, error) {
+	switch format {
+	case FormatDocx, FormatXlsx, FormatPptx:
+		return ooxmlMeta(filePath)
+	case FormatDoc, FormatXls, FormatPpt:
+		return oleMeta(filePath)
+	case FormatOdt, FormatOds, FormatOdp:
+		return odfMeta(filePath)
+	}
+	return document.Meta{}, nil
+}
+
+func ooxmlMeta(filePath string) (document.Meta, error) {
+	var meta document.Meta
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return meta, err
+	}
+	defer pkg.Close()
+
+	// 元素名不带命名空间，dc/cp/dcterms 前缀下的同名元素都能匹配
+	var core struct {
+		Title          string `xml:"title"`
+		Subject        string `xml:"subject"`
+		Creator        string `xml:"creator"`
+		Keywords       string `xml:"keywords"`
+		Description    string `xml:"description"`
+		LastModifiedBy string `xml:"lastModifiedBy"`
+		Created        string `xml:"created"`
+		Modified       string `xml:"modified"`
+	}
+	if pkg.decode(metaPart(pkg, relCoreProperties, "docProps/core.xml"), &core) == nil {
+		meta.Title = strings.TrimSpace(core.Title)
+		meta.Subject = strings.TrimSpace(core.Subject)
+		meta.Author = strings.TrimSpace(core.Creator)
+		meta.Keywords = strings.TrimSpace(core.Keywords)
+		meta.Description = strings.TrimSpace(core.Description)
+		meta.LastModifiedBy = strings.TrimSpace(core.LastModifiedBy)
+		meta.Created = parseIsoDate(strings.TrimSpace(core.Created))
+		meta.Modified = parseIsoDate(strings.TrimSpace(core.Modified))
+	}
+
+	var app struct {
+		Application string `xml:"Application"`
+		Company     string `xml:"Company"`
+		Pages       int    `xml:"Pages"`
+		Words       int    `xml:"Words"`
+		Characters  int    `xml:"Characters"`
+		Slides      int    `xml:"Slides"`
+	}
+	if pkg.decode(metaPart(pkg, relExtendedProperties, "docProps/app.xml"), &app) == nil {
+		meta.Application = strings.TrimSpace(app.Application)
+		meta.Company = strings.TrimSpace(app.Company)
+		meta.Pages, meta.Words, meta.Characters, meta.Slides = app.Pages, app.Words, app.Characters, app.Slides
+	}
+	return meta, nil
+}
+
+// 包级关系中的属性部件，没有关系时取默认路径
+func metaPart(pkg *ooxmlPackage, relType string, fallback string) string {
+	if part := pkg.relTarget("", relType); part != "" {
+		return part
+	}
+	return fallback
+}
+
+func oleMeta(filePath string) (document.Meta, error) {
+	var meta document.Meta
+	streams, err := readOleStreams(filePath, oleSummary, oleDocumentSummary)
+	if err != nil {
+		return meta, err
+	}
+	summary := oleProperties(streams[oleSummary])
+	meta.Title = summary.text("Title")
+	meta.Subject = summary.text("Subject")
+	meta.Author = summary.text("Author")
+	meta.Keywords = summary.text("Keywords")
+	meta.Description = summary.text("Comments")
+	meta.LastModifiedBy = summary.text("LastAuthor")
+	meta.Application = summary.text("AppName")
+	meta.Created = summary.time("CreateTime")
+	meta.Modified = summary.time("LastSaveTime")
+	meta.Pages = summary.number("PageCount")
+	meta.Words = summary.number("WordCount")
+	meta.Characters = summary.number("CharCount")
+
+	docSummary := oleProperties(streams[oleDocumentSummary])
+	meta.Company = docSummary.text("Company")
+	meta.Slides = docSummary.number("Slide count")
+	return meta, nil
+}
+
+// 属性集中的属性，按名称取值
+type oleProps struct {
+	props    map[string]types.Type
+	codePage int
+}
+
+// 解析属性集流，流不存在或损坏时返回空属性集
+func oleProperties(data []byte) oleProps {
+	p := oleProps{props: map[string]types.Type{}}
+	if !validPropertySet(data) {
+		return p
+	}
+	r, err := msoleps.NewFrom(bytes.NewReader(data))
+	if err != nil {
+		return p
+	}
+	for _, prop := range r.Property {
+		if prop != nil && prop.T != nil && prop.Name != "" {
+			p.props[prop.Name] = prop.T
+		}
+	}
+	p.codePage = p.number("CodePage")
+	return p
+}
+
+// msoleps 按文件中的偏移和数量直接切片、分配内存，损坏的流会越界或耗尽内存，
+// 交给它之前先检查属性集头、各节和属性的范围
+func validPropertySet(data []byte) bool {
+	if len(data) < 48 || binary.LittleEndian.Uint16(data) != 0xfffe {
+		return false
+	}
+	offsets := []uint32{binary.LittleEndian.Uint32(data[44:])}
+	switch binary.LittleEndian.Uint32(data[24:]) {
+	case 1:
+	case 2:
+		if len(data) < 68 {
+			return false
+		}
+		offsets = append(offsets, binary.LittleEndian.Uint32(data[64:]))
+	default:
+		return false
+	}
+	for _, off := range offsets {
+		if uint64(off)+8 > uint64(len(data)) {
+			return false
+		}
+		if !validPropertySection(data[off:]) {
+			return false
+		}
+	}
+	return true
+}
+
+// 节头为大小和属性数，随后是 8 字节的属性标识和偏移
+func validPropertySection(b []byte) bool {
+	size := uint64(binary.LittleEndian.Uint32(b))
+	count := uint64(binary.LittleEndian.Uint32(b[4:]))
+	if size > uint64(len(b)) || 8+count*8 > size {
+		return false
+	}
+	b = b[:size]
+	codePage, dict := 0, -1
+	for i := 0; i < int(count); i++ {
+		id := binary.LittleEndian.Uint32(b[8+i*8:])
+		off := uint64(binary.LittleEndian.Uint32(b[12+i*8:]))
+		if off+8 > size {
+			return false
+		}
+		switch {
+		case id == 0:
+			dict = int(off)
+			continue
+		case id == 1:
+			codePage = int(binary.LittleEndian.Uint16(b[off+4:]))
+		}
+		// msoleps 把类型后的两字节为 1 当作向量，按元素数预分配，元素至少占 1 字节
+		if binary.LittleEndian.Uint16(b[off+2:]) == 1 &&
+			uint64(binary.LittleEndian.Uint32(b[off+4:])) > size-off-8 {
+			return false
+		}
+	}
+	return dict < 0 || validPropertyDictionary(b[dict:], codePage)
+}
+
+// 字典为条目数和各条目的标识、名称，按 msoleps 的步进方式检查每个条目不越界
+func validPropertyDictionary(b []byte, codePage int) bool {
+	num := uint64(binary.LittleEndian.Uint32(b))
+	if num > uint64(len(b))/8 {
+		return false
+	}
+	p := uint64(4)
+	for i := uint64(0); i < num; i++ {
+		if p+8 > uint64(len(b)) {
+			return false
+		}
+		l := uint64(binary.LittleEndian.Uint32(b[p+4:]))
+		if codePage == 1200 {
+			p += 8 + l*2 + l%2*2
+		} else {
+			p += 8 + l
+		}
+		if p > uint64(len(b)) {
+			return false
+		}
+	}
+	return true
+}
+
+// 字符串属性，ANSI 字符串按属性集的代码页解码
+func (p oleProps) text(name string) string {
+	t, ok := p.props[name]
+	if !ok {
+		return ""
+	}
+	if cs, ok := t.(*types.CodeString); ok && p.codePage != 1200 {
+		chars := cs.Chars
+		if i := bytes.IndexByte(chars, 0); i >= 0 {
+			chars = chars[:i]
+		}
+		if enc := codePageEncoding(p.codePage); enc != nil {
+			if out, err := enc.NewDecoder().Bytes(chars); err == nil {
+				chars = out
+			}
+		}
+		return strings.TrimSpace(string(chars))
+	}
+	return strings.TrimSpace(t.String())
+}
+
+func (p oleProps) number(name string) int {
+	t, ok := p.props[name]
+	if !ok {
+		return 0
+	}
+	n, _ := strconv.Atoi(t.String())
+	// 代码页以有符号 16 位整数保存，1200 以上的值可能为负
+	if name == "CodePage" && n < 0 {
+		n += 1 << 16
+	}
+	return n
+}
+
+func (p oleProps) time(name string) *time.Time {
+	ft, ok := p.props[name].(types.FileTime)
+	if !ok {
+		return nil
+	}
+	t := ft.Time()
+	// 未设置的时间为 1601-01-01，部分程序写入 0 后解析为 1970-01-01
+	if t.Year() <= 1601 || t.Unix() == 0 {
+		return nil
+	}
+	t = t.Local()
+	return &t
+}
+
+func odfMeta(filePath string) (document.Meta, error) {
+	var meta document.Meta
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return meta, err
+	}
+	defer zr.Close()
+
+	var doc struct {
+		Meta struct {
+			Title          string   `xml:"title"`
+			Subject        string   `xml:"subject"`
+			InitialCreator string   `xml:"initial-creator"`
+			Creator        string   `xml:"creator"`
+			Keywords       []string `xml:"keyword"`
+			Description    string   `xml:"description"`
+			CreationDate   string   `xml:"creation-date"`
+			Date           string   `xml:"date"`
+			Generator      string   `xml:"generator"`
+			Statistic      struct {
+				Pages      int `xml:"page-count,attr"`
+				Words      int `xml:"word-count,attr"`
+				Characters int `xml:"character-count,attr"`
+			} `xml:"document-statistic"`
+		} `xml:"meta"`
+	}
+	for _, f := range zr.File {
+		if f.Name != "meta.xml" {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return meta, err
+		}
+		err = xml.NewDecoder(rc).Decode(&doc)
+		rc.Close()
+		if err != nil {
+			return meta, err
+		}
+	}
+	m := doc.Meta
+	meta.Title = strings.TrimSpace(m.Title)
+	meta.Subject = strings.TrimSpace(m.Subject)
+	// 没有初始作者时以最后修改者作为作者
+	meta.Author = strings.TrimSpace(m.InitialCreator)
+	meta.LastModifiedBy = strings.TrimSpace(m.Creator)
+	if meta.Author == "" {
+		meta.Author = meta.LastModifiedBy
+	}
+	meta.Keywords = strings.Join(m.Keywords, ", ")
+	meta.Description = strings.TrimSpace(m.Description)
+	meta.Created = parseIsoDate(strings.TrimSpace(m.CreationDate))
+	meta.Modified = parseIsoDate(strings.TrimSpace(m.Date))
+	meta.Application = strings.TrimSpace(m.Generator)
+	meta.Pages, meta.Words, meta.Characters = m.Statistic.Pages, m.Statistic.Words, m.Statistic.Characters
+	return meta, nil
+}
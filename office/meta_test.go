@@ -0,0 +1,83 @@
+package office
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// 只有一节的 SummaryInformation 流，props 为属性标识和值（含类型头）
+func propertySetStream(props map[uint32][]byte) []byte {
+	le := binary.LittleEndian
+	head := make([]byte, 48)
+	le.PutUint16(head, 0xfffe)
+	le.PutUint32(head[24:], 1)
+	// SummaryInformation 的 FMTID F29F85E0-4FF9-1068-AB91-08002B27B3D9
+	copy(head[28:], []byte{0xe0, 0x85, 0x9f, 0xf2, 0xf9, 0x4f, 0x68, 0x10, 0xab, 0x91, 0x08, 0x00, 0x2b, 0x27, 0xb3, 0xd9})
+	le.PutUint32(head[44:], 48)
+
+	section := make([]byte, 8+len(props)*8)
+	le.PutUint32(section[4:], uint32(len(props)))
+	i := 0
+	for _, id := range []uint32{0, 1, 2, 0x13} {
+		v, ok := props[id]
+		if !ok {
+			continue
+		}
+		le.PutUint32(section[8+i*8:], id)
+		le.PutUint32(section[12+i*8:], uint32(len(section)))
+		section = append(section, v...)
+		i++
+	}
+	le.PutUint32(section, uint32(len(section)))
+	return append(head, section...)
+}
+
+// 带类型头的 ANSI 字符串
+func lpstr(s string) []byte {
+	b := []byte{30, 0, 0, 0, 0, 0, 0, 0}
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(s)+1))
+	return append(append(b, s...), 0)
+}
+
+func TestOleProperties(t *testing.T) {
+	data := propertySetStream(map[uint32][]byte{
+		1: {2, 0, 0, 0, 0xe9, 0xfd, 0, 0}, // 代码页 65001
+		2: lpstr("标题"),
+	})
+	if got := oleProperties(data).text("Title"); got != "标题" {
+		t.Fatalf("Title = %q", got)
+	}
+}
+
+func TestOlePropertiesCorrupt(t *testing.T) {
+	le := binary.LittleEndian
+	valid := propertySetStream(map[uint32][]byte{2: lpstr("x")})
+	corrupt := map[string]func([]byte) []byte{
+		"节偏移越界":   func(b []byte) []byte { le.PutUint32(b[44:], 0xfffffff0); return b },
+		"节大小越界":   func(b []byte) []byte { le.PutUint32(b[48:], 0xffffffff); return b },
+		"属性数过大":   func(b []byte) []byte { le.PutUint32(b[52:], 0x20000000); return b },
+		"属性偏移越界":  func(b []byte) []byte { le.PutUint32(b[60:], 0xfffffff0); return b },
+		"两节但头部不全": func(b []byte) []byte { le.PutUint32(b[24:], 2); return b[:60] },
+		"截断":      func(b []byte) []byte { return b[:len(b)-4] },
+	}
+	for name, f := range corrupt {
+		b := f(append([]byte(nil), valid...))
+		if p := oleProperties(b); len(p.props) != 0 {
+			t.Errorf("%s: props = %v", name, p.props)
+		}
+	}
+
+	// 元素数极大的向量和越界的字典
+	vector := []byte{30, 0, 1, 0, 0xff, 0xff, 0xff, 0xff}
+	dict := []byte{0xff, 0xff, 0xff, 0x0f}
+	longName := []byte{1, 0, 0, 0, 2, 0, 0, 0, 0, 0, 0, 0x7f, 'a', 0}
+	for name, props := range map[string]map[uint32][]byte{
+		"向量":    {0x13: vector},
+		"字典条目数": {0: dict},
+		"字典名称":  {0: longName},
+	} {
+		if p := oleProperties(propertySetStream(props)); len(p.props) != 0 {
+			t.Errorf("%s: props = %v", name, p.props)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package office
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// OpenDocument（odt/ods/odp）为 zip 包，正文在 content.xml，命名样式在 styles.xml。
+// content.xml 读成元素树后按元素遍历，段落、列表、表格转为与 word 相同的结构。
+
+const (
+	nsOdfOffice       = "urn:oasis:names:tc:opendocument:xmlns:office:1.0"
+	nsOdfText         = "urn:oasis:names:tc:opendocument:xmlns:text:1.0"
+	nsOdfTable        = "urn:oasis:names:tc:opendocument:xmlns:table:1.0"
+	nsOdfStyle        = "urn:oasis:names:tc:opendocument:xmlns:style:1.0"
+	nsOdfDraw         = "urn:oasis:names:tc:opendocument:xmlns:drawing:1.0"
+	nsOdfPresentation = "urn:oasis:names:tc:opendocument:xmlns:presentation:1.0"
+	nsOdfFo           = "urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"
+)
+
+// 元素树的节点，文本节点只有 text
+type odfNode struct {
+	name  xml.Name
+	attrs []xml.Attr
+	nodes []*odfNode
+	text  string
+}
+
+func (n *odfNode) is(space string, local string) bool {
+	return n.name.Space == space && n.name.Local == local
+}
+
+func (n *odfNode) attr(space string, local string) string {
+	for _, a := range n.attrs {
+		if a.Name.Space == space && a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// 正整数属性，缺省或无效时返回 def
+func (n *odfNode) intAttr(space string, local string, def int) int {
+	v, err := strconv.Atoi(n.attr(space, local))
+	if err != nil || v < 1 {
+		return def
+	}
+	return v
+}
+
+// 第一个指定名称的子元素
+func (n *odfNode) child(space string, local string) *odfNode {
+	for _, c := range n.nodes {
+		if c.is(space, local) {
+			return c
+		}
+	}
+	return nil
+}
+
+// 按路径逐级查找子元素，路径中的元素在同一命名空间下
+func (n *odfNode) find(space string, path ...string) *odfNode {
+	for _, local := range path {
+		if n = n.child(space, local); n == nil {
+			return nil
+		}
+	}
+	return n
+}
+
+// 读取包中的 xml 部件为元素树
+func readOdfXml(pkg *ooxmlPackage, part string) (*odfNode, error) {
+	rc, err := pkg.open(part)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	root := &odfNode{}
+	stack := []*odfNode{root}
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		top := stack[len(stack)-1]
+		switch t := tok.(type) {
+		case xml.StartElement:
+			n := &odfNode{name: t.Name, attrs: t.Copy().Attr}
+			top.nodes = append(top.nodes, n)
+			stack = append(stack, n)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		case xml.CharData:
+			top.nodes = append(top.nodes, &odfNode{text: string(t)})
+		}
+	}
+	if len(root.nodes) == 0 {
+		return nil, errors.New("empty part")
+	}
+	return root, nil
+}
+
+// 读取 content.xml 中 office:body 下指定类型的内容元素，如 office:text
+func readOdfBody(pkg *ooxmlPackage, kind string) (*odfNode, *odfStyles, error) {
+	content, err := readOdfXml(pkg, "content.xml")
+	if err != nil {
+		return nil, nil, err
+	}
+	styles := &odfStyles{styles: map[string]*odfStyle{}, lists: map[string][]odfListLevel{}, masters: map[string]string{}}
+	if doc, err := readOdfXml(pkg, "styles.xml"); err == nil {
+		styles.load(doc)
+	}
+	styles.load(content)
+
+	body := content.find(nsOdfOffice, "document-content", "body", kind)
+	if body == nil {
+		return nil, nil, errors.New("missing body")
+	}
+	return body, styles, nil
+}
+
+// 样式，格式属性为空时继承父样式
+type odfStyle struct {
+	parent    string
+	automatic bool   // 自动样式，由程序生成，名称如 P1、T1
+	weight    string // fo:font-weight
+	fontStyle string // fo:font-style
+	hidden    bool   // 隐藏的工作表或幻灯片
+}
+
+// 列表样式的一级
+type odfListLevel struct {
+	format        string // 同 word 的 numFmt，项目符号为 bullet
+	prefix        string
+	suffix        string
+	displayLevels int // 编号中显示的级数，如 2 表示 1.1
+	start         int
+}
+
+type odfStyles struct {
+	styles  map[string]*odfStyle      // 族/名称 -> 样式
+	lists   map[string][]odfListLevel // 列表样式名称 -> 各级定义
+	masters map[string]string         // 母版名称 -> 显示名称
+}
+
+// odf 编号格式 -> word 的 numFmt
+var odfNumFormats = map[string]string{
+	"1": "decimal", "a": "lowerLetter", "A": "upperLetter", "i": "lowerRoman", "I": "upperRoman",
+	"一, 二, 三, ...": "chineseCounting", "壹, 贰, 叁, ...": "chineseCounting", "①, ②, ③, ...": "decimalEnclosedCircle",
+}
+
+// 读取文档中 office:styles、office:automatic-styles、office:master-styles 下的定义
+func (s *odfStyles) load(doc *odfNode) {
+	for _, root := range doc.nodes {
+		for _, group := range root.nodes {
+			if group.name.Space != nsOdfOffice {
+				continue
+			}
+			for _, n := range group.nodes {
+				switch {
+				case n.is(nsOdfStyle, "style"):
+					s.loadStyle(n, group.name.Local == "automatic-styles")
+				case n.is(nsOdfText, "list-style"):
+					s.loadList(n)
+				case n.is(nsOdfStyle, "master-page"):
+					name := n.attr(nsOdfStyle, "display-name")
+					if name == "" {
+						name = odfDisplayName(n.attr(nsOdfStyle, "name"))
+					}
+					s.masters[n.attr(nsOdfStyle, "name")] = name
+				}
+			}
+		}
+	}
+}
+
+func (s *odfStyles) loadStyle(n *odfNode, automatic bool) {
+	st := &odfStyle{parent: n.attr(nsOdfStyle, "parent-style-name"), automatic: automatic}
+	for _, p := range n.nodes {
+		switch {
+		case p.is(nsOdfStyle, "text-properties"):
+			st.weight = p.attr(nsOdfFo, "font-weight")
+			st.fontStyle = p.attr(nsOdfFo, "font-style")
+		case p.is(nsOdfStyle, "table-properties"):
+			st.hidden = p.attr(nsOdfTable, "display") == "false"
+		case p.is(nsOdfStyle, "drawing-page-properties"):
+			st.hidden = p.attr(nsOdfPresentation, "visibility") == "hidden"
+		}
+	}
+	s.styles[n.attr(nsOdfStyle, "family")+"/"+n.attr(nsOdfStyle, "name")] = st
+}
+
+func (s *odfStyles) loadList(n *odfNode) {
+	var levels []odfListLevel
+	for _, l := range n.nodes {
+		if l.name.Space != nsOdfText {
+			continue
+		}
+		level := odfListLevel{format: "bullet", displayLevels: 1, start: 1}
+		switch l.name.Local {
+		case "list-level-style-number":
+			level.format = odfNumFormats[l.attr(nsOdfStyle, "num-format")]
+			if level.format == "" {
+				level.format = "none"
+			}
+			level.prefix = l.attr(nsOdfStyle, "num-prefix")
+			level.suffix = l.attr(nsOdfStyle, "num-suffix")
+			level.displayLevels = minInt(maxInt(l.intAttr(nsOdfText, "display-levels", 1), 1), odfMaxListLevels)
+			level.start = clampListNumber(l.intAttr(nsOdfText, "start-value", 1))
+		case "list-level-style-bullet", "list-level-style-image":
+		default:
+			continue
+		}
+		// ODF 列表最多 10 级，超出范围的级别忽略
+		i := l.intAttr(nsOdfText, "level", 1) - 1
+		if i < 0 || i >= odfMaxListLevels {
+			continue
+		}
+		for len(levels) <= i {
+			levels = append(levels, odfListLevel{format: "bullet", displayLevels: 1, start: 1})
+		}
+		levels[i] = level
+	}
+	s.lists[n.attr(nsOdfStyle, "name")] = levels
+}
+
+func (s *odfStyles) get(family string, name string) *odfStyle {
+	if s == nil || name == "" {
+		return nil
+	}
+	return s.styles[family+"/"+name]
+}
+
+// 沿父样式查找第一个满足条件的样式，最多查 10 级防止循环引用
+func (s *odfStyles) lookup(family string, name string, fn func(st *odfStyle) bool) *odfStyle {
+	for i := 0; i < 10; i++ {
+		st := s.get(family, name)
+		if st == nil {
+			return nil
+		}
+		if fn(st) {
+			return st
+		}
+		name = st.parent
+	}
+	return nil
+}
+
+// 文字样式的加粗和倾斜
+func (s *odfStyles) format(family string, name string, bold bool, italic bool) (bool, bool) {
+	if st := s.lookup(family, name, func(st *odfStyle) bool { return st.weight != "" }); st != nil {
+		n, _ := strconv.Atoi(st.weight)
+		bold = st.weight == "bold" || n >= 600
+	}
+	if st := s.lookup(family, name, func(st *odfStyle) bool { return st.fontStyle != "" }); st != nil {
+		italic = st.fontStyle == "italic" || st.fontStyle == "oblique"
+	}
+	return bold, italic
+}
+
+// 自动样式替换为其继承的命名样式
+func (s *odfStyles) named(family string, name string) string {
+	for i := 0; i < 10; i++ {
+		st := s.get(family, name)
+		if st == nil || !st.automatic {
+			break
+		}
+		name = st.parent
+	}
+	return name
+}
+
+// 样式名中的特殊字符编码为 _xx_，如 Heading_20_1 为 Heading 1
+func odfDisplayName(name string) string {
+	var sb strings.Builder
+	for {
+		i := strings.Index(name, "_")
+		if i < 0 {
+			break
+		}
+		j := strings.Index(name[i+1:], "_")
+		if j < 0 {
+			break
+		}
+		code, err := strconv.ParseUint(name[i+1:i+1+j], 16, 32)
+		if err != nil {
+			sb.WriteString(name[:i+1])
+			name = name[i+1:]
+			continue
+		}
+		sb.WriteString(name[:i])
+		sb.WriteRune(rune(code))
+		name = name[i+j+2:]
+	}
+	sb.WriteString(name)
+	return sb.String()
+}
+
+// 正文转换，列表编号按列表样式分别计数
+type odfExtractor struct {
+	styles   *odfStyles
+	counters map[string][]int
+}
+
+func newOdfExtractor(styles *odfStyles) *odfExtractor {
+	return &odfExtractor{styles: styles, counters: map[string][]int{}}
+}
+
+// 可以直接展开的容器：分节、目录和索引的正文
+var odfContainers = map[string]bool{
+	"section": true, "index-body": true, "index-title": true, "table-of-content": true, "illustration-index": true,
+	"table-index": true, "object-index": true, "user-index": true, "alphabetical-index": true, "bibliography": true,
+}
+
+// 容器中的段落、标题、列表和表格，按文档顺序排列
+func (e *odfExtractor) blocks(n *odfNode) []WordBlock {
+	var blocks []WordBlock
+	for _, c := range n.nodes {
+		switch {
+		case c.is(nsOdfText, "p"), c.is(nsOdfText, "h"):
+			if para := e.paragraph(c); para != nil {
+				blocks = append(blocks, WordBlock{Paragraph: para})
+			}
+		case c.is(nsOdfText, "list"):
+			blocks = append(blocks, e.list(c, "", 0)...)
+		case c.is(nsOdfTable, "table"):
+			if table := e.table(c); table != nil {
+				blocks = append(blocks, WordBlock{Table: table})
+			}
+		case c.name.Space == nsOdfText && odfContainers[c.name.Local]:
+			blocks = append(blocks, e.blocks(c)...)
+		}
+	}
+	return blocks
+}
+
+func (e *odfExtractor) paragraph(n *odfNode) *WordParagraph {
+	style := n.attr(nsOdfText, "style-name")
+	bold, italic := e.styles.format("paragraph", style, false, false)
+	para := WordParagraph{Spans: mergeSpans(e.spans(n, bold, italic))}
+	for _, s := range para.Spans {
+		para.Text += s.Text
+	}
+	if strings.TrimSpace(para.Text) == "" {
+		return nil
+	}
+	para.Style = e.styles.named("paragraph", style)
+	if n.is(nsOdfText, "h") {
+		para.HeadingLevel = minInt(n.intAttr(nsOdfText, "outline-level", 1), 9)
+	}
+	return &para
+}
+
+// 段落中的文字片段，超链接、域等元素取其显示的文字；脚注、批注和段落中的图形不计入
+func (e *odfExtractor) spans(n *odfNode, bold bool, italic bool) []WordSpan {
+	var spans []WordSpan
+	for _, c := range n.nodes {
+		switch {
+		case c.name.Local == "":
+			spans = append(spans, WordSpan{Text: c.text, Bold: bold, Italic: italic})
+		case c.is(nsOdfText, "s"):
+			// 连续空格数来自文件，限制长度
+			spans = append(spans, WordSpan{Text: strings.Repeat(" ", minInt(c.intAttr(nsOdfText, "c", 1), odfMaxSpaces)), Bold: bold, Italic: italic})
+		case c.is(nsOdfText, "tab"):
+			spans = append(spans, WordSpan{Text: "\t", Bold: bold, Italic: italic})
+		case c.is(nsOdfText, "line-break"):
+			spans = append(spans, WordSpan{Text: "\n", Bold: bold, Italic: italic})
+		case c.is(nsOdfText, "span"):
+			b, i := e.styles.format("text", c.attr(nsOdfText, "style-name"), bold, italic)
+			spans = append(spans, e.spans(c, b, i)...)
+		case c.is(nsOdfText, "note"), c.is(nsOdfText, "ruby-text"):
+		case c.name.Space == nsOdfText:
+			spans = append(spans, e.spans(c, bold, italic)...)
+		}
+	}
+	return spans
+}
+
+// 段落的纯文本，不区分格式
+func odfText(n *odfNode) string {
+	var sb strings.Builder
+	for _, s := range (&odfExtractor{}).spans(n, false, false) {
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
+
+// 列表，嵌套的列表层级加一；列表样式只写在最外层
+func (e *odfExtractor) list(n *odfNode, style string, level int) []WordBlock {
+	if s := n.attr(nsOdfText, "style-name"); s != "" {
+		style = s
+	}
+	// 新的顶层列表重新编号，continue-numbering 时接着上一个列表
+	if level == 0 && n.attr(nsOdfText, "continue-numbering") != "true" && n.attr(nsOdfText, "continue-list") == "" {
+		delete(e.counters, style)
+	}
+	var blocks []WordBlock
+	for _, item := range n.nodes {
+		if !item.is(nsOdfText, "list-item") && !item.is(nsOdfText, "list-header") {
+			continue
+		}
+		numbered := item.is(nsOdfText, "list-item")
+		for _, c := range item.nodes {
+			switch {
+			case c.is(nsOdfText, "list"):
+				blocks = append(blocks, e.list(c, style, level+1)...)
+			case c.is(nsOdfText, "p"), c.is(nsOdfText, "h"):
+				para := e.paragraph(c)
+				if para == nil {
+					continue
+				}
+				// 列表项中只有第一个段落带编号
+				if numbered && para.HeadingLevel == 0 {
+					para.IsList, para.ListLevel = true, level
+					para.Numbering, para.Ordered = e.nextNumber(style, level, item.intAttr(nsOdfText, "start-value", 0))
+					numbered = false
+				}
+				blocks = append(blocks, WordBlock{Paragraph: para})
+			case c.is(nsOdfTable, "table"):
+				if table := e.table(c); table != nil {
+					blocks = append(blocks, WordBlock{Table: table})
+				}
+			}
+		}
+	}
+	return blocks
+}
+
+// 计算列表项编号，start 大于 0 时从该值重新计数
+func (e *odfExtractor) nextNumber(style string, level int, start int) (string, bool) {
+	if level >= odfMaxListLevels {
+		level = odfMaxListLevels - 1
+	}
+	levels := e.styles.lists[style]
+	def := func(i int) odfListLevel {
+		if i < len(levels) {
+			return levels[i]
+		}
+		return odfListLevel{format: "bullet", displayLevels: 1, start: 1}
+	}
+	counters, ok := e.counters[style]
+	if !ok {
+		counters = make([]int, odfMaxListLevels)
+		e.counters[style] = counters
+	}
+	switch {
+	case start > 0:
+		counters[level] = clampListNumber(start)
+	case counters[level] == 0:
+		counters[level] = def(level).start
+	default:
+		counters[level] = clampListNumber(counters[level] + 1)
+	}
+	// 上级编号递增后，下级重新计数
+	for i := level + 1; i < len(counters); i++ {
+		counters[i] = 0
+	}
+
+	d := def(level)
+	switch d.format {
+	case "bullet":
+		return "•", false
+	case "none":
+		return "", true
+	}
+	parts := make([]string, 0, d.displayLevels)
+	for i := level - d.displayLevels + 1; i <= level; i++ {
+		if i < 0 {
+			continue
+		}
+		n := counters[i]
+		if n == 0 {
+			n = def(i).start
+		}
+		parts = append(parts, formatNumber(n, def(i).format))
+	}
+	return d.prefix + strings.Join(parts, ".") + d.suffix, true
+}
+
+// 最多展开的行列数和表格的单元格总数，整行整列的空格式常以极大的重复数写出
+const (
+	odfMaxRows  = 1048576
+	odfMaxCols  = 16384
+	odfMaxCells = 1000000
+)
+
+// text:s 最多展开的空格数
+const odfMaxSpaces = 1024
+
+// 列表的最大级数
+const odfMaxListLevels = 10
+
+// 纵向合并的起始单元格和剩余行数
+type odfVSpan struct {
+	pos  cellPos
+	left int
+}
+
+// 表格转为与 word 相同的结构：横向合并的单元格由起始单元格的 ColSpan 覆盖，
+// 纵向合并覆盖的单元格标记为 Merged；空行只在后面有内容时展开
+func (e *odfExtractor) table(n *odfNode) *WordTable {
+	t := &WordTable{}
+	vMerge := map[int]*odfVSpan{} // 起始网格列 -> 纵向合并
+	cells := 0
+	pending := 0 // 尚未展开的空行
+rows:
+	for _, tr := range odfTableRows(n) {
+		repeat := tr.intAttr(nsOdfTable, "number-rows-repeated", 1)
+		for k := 0; k < repeat && len(t.Rows)+pending < odfMaxRows && cells < odfMaxCells; k++ {
+			row, cols, steps := e.tableRow(tr, len(t.Rows)+pending, t, vMerge)
+			cells += steps
+			if len(row.Cells) == 0 {
+				// 空行不改变合并状态，其余重复行也都是空行
+				pending += repeat - k
+				break
+			}
+			if cells+pending > odfMaxCells {
+				// 该行的纵向合并已登记，不再读取后面的行
+				break rows
+			}
+			for ; pending > 0; pending-- {
+				t.Rows = append(t.Rows, WordRow{})
+				cells++
+			}
+			t.Rows = append(t.Rows, row)
+			c := row.Cells[len(row.Cells)-1]
+			t.Cols = maxInt(t.Cols, maxInt(cols, c.Col+c.ColSpan))
+		}
+	}
+	if len(t.Rows) == 0 {
+		return nil
+	}
+	return t
+}
+
+// 表格的一行，r 为该行的行号；空单元格只在后面有内容时展开。
+// 返回有内容的列数和逐格处理的单元格数，后者计入表格的单元格总数
+func (e *odfExtractor) tableRow(tr *odfNode, r int, t *WordTable, vMerge map[int]*odfVSpan) (WordRow, int, int) {
+	row := WordRow{}
+	col, steps := 0, 0
+	pending := 0 // col 之前尚未展开的空单元格
+	flush := func() {
+		steps += pending
+		for i := col - pending; i < col; i++ {
+			row.Cells = append(row.Cells, WordCell{Col: i, ColSpan: 1, RowSpan: 1})
+		}
+		pending = 0
+	}
+	for _, tc := range tr.nodes {
+		covered := tc.is(nsOdfTable, "covered-table-cell")
+		if !covered && !tc.is(nsOdfTable, "table-cell") {
+			continue
+		}
+		repeat := minInt(tc.intAttr(nsOdfTable, "number-columns-repeated", 1), odfMaxCols-col)
+		if covered {
+			for k := 0; k < repeat; k++ {
+				if len(vMerge) == 0 {
+					// 没有纵向合并时横向合并覆盖的单元格直接跳过
+					col += repeat - k
+					break
+				}
+				steps++
+				if v, ok := vMerge[col]; ok {
+					flush()
+					origin := &t.Rows[v.pos.row].Cells[v.pos.cell]
+					row.Cells = append(row.Cells, WordCell{Col: col, ColSpan: origin.ColSpan, Merged: true})
+					if v.left--; v.left == 0 {
+						delete(vMerge, col)
+					}
+				}
+				col++
+			}
+			continue
+		}
+		cell := WordCell{
+			ColSpan: tc.intAttr(nsOdfTable, "number-columns-spanned", 1),
+			RowSpan: tc.intAttr(nsOdfTable, "number-rows-spanned", 1),
+			Blocks:  e.blocks(tc),
+		}
+		if len(cell.Blocks) == 0 && cell.ColSpan == 1 && cell.RowSpan == 1 {
+			pending += repeat
+			col += repeat
+			continue
+		}
+		flush()
+		cell.Text = blocksText(cell.Blocks)
+		steps += repeat
+		for k := 0; k < repeat; k++ {
+			cell.Col = col
+			if cell.RowSpan > 1 {
+				vMerge[col] = &odfVSpan{pos: cellPos{row: r, cell: len(row.Cells)}, left: cell.RowSpan - 1}
+			}
+			row.Cells = append(row.Cells, cell)
+			col++
+		}
+	}
+	return row, col - pending, steps
+}
+
+// 表格中的所有行，展开表头行和行组，重复行不展开
+func odfTableRows(n *odfNode) []*odfNode {
+	var rows []*odfNode
+	for _, c := range n.nodes {
+		switch {
+		case c.is(nsOdfTable, "table-row"):
+			rows = append(rows, c)
+		case c.is(nsOdfTable, "table-header-rows"), c.is(nsOdfTable, "table-row-group"), c.is(nsOdfTable, "table-rows"):
+			rows = append(rows, odfTableRows(c)...)
+		}
+	}
+	return rows
+}
+
+// odt 文件转 word 结构化内容，只读取正文
+func parseOdt(filePath string) (*WordDocument, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	body, styles, err := readOdfBody(pkg, "text")
+	if err != nil {
+		return nil, errors.New("读取文件内容失败！")
+	}
+	return &WordDocument{Blocks: newOdfExtractor(styles).blocks(body)}, nil
+}
+
+// zip 包中 mimetype 文件标明的 OpenDocument 格式，不是 OpenDocument 时返回空
+func odfFileFormat(filePath string) string {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return ""
+	}
+	defer pkg.Close()
+	mimeType, err := pkg.read("mimetype")
+	if err != nil {
+		return ""
+	}
+	return odfMimeTypes[strings.TrimSpace(string(mimeType))]
+}
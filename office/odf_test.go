@@ -0,0 +1,133 @@
+package office
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const odtNs = `xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0" xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0" xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0" xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"`
+
+// 只含 content.xml 的 odt，body 为 office:text 中的内容
+func writeOdt(t *testing.T, body string) string {
+	t.Helper()
+	return writeOdtStyled(t, "", body)
+}
+
+// styles 为 office:automatic-styles 中的内容
+func writeOdtStyled(t *testing.T, styles string, body string) string {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "test.odt")
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zw := zip.NewWriter(f)
+	w, _ := zw.CreateHeader(&zip.FileHeader{Name: "mimetype", Method: zip.Store})
+	w.Write([]byte("application/vnd.oasis.opendocument.text"))
+	w, _ = zw.Create("content.xml")
+	w.Write([]byte(`<office:document-content ` + odtNs + `><office:automatic-styles>` + styles + `</office:automatic-styles><office:body><office:text>` + body + `</office:text></office:body></office:document-content>`))
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	return name
+}
+
+func TestOdtTableMerge(t *testing.T) {
+	name := writeOdt(t, `<table:table>
+<table:table-row><table:table-cell table:number-rows-spanned="2"><text:p>A</text:p></table:table-cell><table:table-cell table:number-columns-spanned="2"><text:p>B</text:p></table:table-cell><table:covered-table-cell/></table:table-row>
+<table:table-row><table:covered-table-cell/><table:table-cell/><table:table-cell><text:p>C</text:p></table:table-cell></table:table-row>
+</table:table>`)
+	doc, err := parseOdt(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables := doc.Tables()
+	if len(tables) != 1 {
+		t.Fatalf("tables = %d", len(tables))
+	}
+	tbl := tables[0]
+	if tbl.Cols != 3 || len(tbl.Rows) != 2 {
+		t.Fatalf("cols = %d, rows = %d", tbl.Cols, len(tbl.Rows))
+	}
+	want := [][]string{{"A", "B", "B"}, {"A", "", "C"}}
+	for i, row := range tbl.Grid() {
+		if strings.Join(row, "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, row, want[i])
+		}
+	}
+	if c := tbl.Rows[1].Cells[0]; !c.Merged {
+		t.Errorf("cell = %+v, want merged", c)
+	}
+}
+
+func TestOdtTableRepeated(t *testing.T) {
+	// 整表的空格式写成极大的重复数，只保留有内容的行列
+	name := writeOdt(t, `<table:table>
+<table:table-row><table:table-cell><text:p>A</text:p></table:table-cell><table:table-cell table:number-columns-repeated="16384"/></table:table-row>
+<table:table-row table:number-rows-repeated="1048576"><table:table-cell table:number-columns-repeated="16384"/></table:table-row>
+</table:table>`)
+	doc, err := parseOdt(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := doc.Tables()[0]
+	if tbl.Cols != 1 || len(tbl.Rows) != 1 {
+		t.Fatalf("cols = %d, rows = %d", tbl.Cols, len(tbl.Rows))
+	}
+}
+
+func TestOdtTableRepeatedContent(t *testing.T) {
+	// 有内容的重复行按单元格总数截断
+	name := writeOdt(t, `<table:table><table:table-row table:number-rows-repeated="1048576"><table:table-cell table:number-columns-repeated="16384"><text:p>x</text:p></table:table-cell></table:table-row></table:table>`)
+	doc, err := parseOdt(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := doc.Tables()[0]
+	cells := 0
+	for _, row := range tbl.Rows {
+		cells += len(row.Cells)
+	}
+	if cells > odfMaxCells+odfMaxCols {
+		t.Fatalf("cells = %d", cells)
+	}
+}
+
+func TestOdtSpaces(t *testing.T) {
+	name := writeOdt(t, `<text:p>a<text:s text:c="3"/>b<text:s text:c="2000000000"/>c</text:p>`)
+	doc, err := parseOdt(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := doc.Paragraphs()[0].Text
+	if !strings.HasPrefix(text, "a   b ") || len(text) != 6+odfMaxSpaces {
+		t.Fatalf("text = %q...（%d 字节）", text[:minInt(len(text), 10)], len(text))
+	}
+}
+
+func TestOdtListRange(t *testing.T) {
+	// 超出范围的级别、显示级数和起始值
+	name := writeOdtStyled(t, `<text:list-style style:name="L1">
+<text:list-level-style-number text:level="1500000000" style:num-format="1"/>
+<text:list-level-style-number text:level="1" style:num-format="a" style:num-suffix="." text:display-levels="2000000000" text:start-value="9000000000000000000"/>
+</text:list-style>`, `<text:list text:style-name="L1">
+<text:list-item><text:p>a</text:p></text:list-item>
+<text:list-item><text:p>b</text:p></text:list-item>
+<text:list-item text:start-value="-5"><text:p>c</text:p></text:list-item>
+</text:list>`)
+	doc, err := parseOdt(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range doc.Paragraphs() {
+		got = append(got, p.Numbering)
+	}
+	if strings.Join(got, " ") != "32767. 32767. 32767." {
+		t.Fatalf("numbering = %q", got)
+	}
+}
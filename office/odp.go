@@ -0,0 +1,98 @@
+package office
+
+import (
+	"errors"
+)
+
+// odp 的每张幻灯片为 draw:page，形状为 draw:frame（文本框、表格）和各种图形，
+// presentation:class 标明占位符类型，备注在 presentation:notes 中。
+
+// 不作为正文的占位符：页码、日期、页眉页脚
+var odpSkipClasses = map[string]bool{"page-number": true, "date-time": true, "header": true, "footer": true}
+
+func parseOdp(filePath string) (*Presentation, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	body, styles, err := readOdfBody(pkg, "presentation")
+	if err != nil {
+		return nil, errors.New("读取演示文稿失败！")
+	}
+	e := newOdfExtractor(styles)
+	pres := &Presentation{}
+	for _, page := range body.nodes {
+		if !page.is(nsOdfDraw, "page") {
+			continue
+		}
+		slide := Slide{Number: len(pres.Slides) + 1, Layout: styles.masters[page.attr(nsOdfDraw, "master-page-name")]}
+		if st := styles.get("drawing-page", page.attr(nsOdfDraw, "style-name")); st != nil {
+			slide.Hidden = st.hidden
+		}
+		e.slideShapes(page, &slide)
+		if notes := page.child(nsOdfPresentation, "notes"); notes != nil {
+			for _, b := range e.shapeBlocks(notes, "notes") {
+				if b.Paragraph != nil {
+					slide.Notes = append(slide.Notes, b.Paragraph.Text)
+				}
+			}
+		}
+		pres.Slides = append(pres.Slides, slide)
+	}
+	return pres, nil
+}
+
+// 按文档顺序读取形状中的文字，组合递归展开，第一个标题占位符作为标题
+func (e *odfExtractor) slideShapes(n *odfNode, slide *Slide) {
+	for _, c := range n.nodes {
+		if c.name.Space != nsOdfDraw {
+			continue
+		}
+		if c.name.Local == "g" {
+			e.slideShapes(c, slide)
+			continue
+		}
+		class := c.attr(nsOdfPresentation, "class")
+		if odpSkipClasses[class] {
+			continue
+		}
+		blocks := e.frameBlocks(c)
+		if class == "title" && slide.Title == "" {
+			slide.Title = blocksText(blocks)
+			continue
+		}
+		slide.Body = append(slide.Body, blocks...)
+	}
+}
+
+// 指定占位符类型的形状中的文字
+func (e *odfExtractor) shapeBlocks(n *odfNode, class string) []WordBlock {
+	var blocks []WordBlock
+	for _, c := range n.nodes {
+		if c.name.Space == nsOdfDraw && c.attr(nsOdfPresentation, "class") == class {
+			blocks = append(blocks, e.frameBlocks(c)...)
+		}
+	}
+	return blocks
+}
+
+// 文本框和表格在 draw:frame 之下，其他图形的文字直接在图形元素中
+func (e *odfExtractor) frameBlocks(n *odfNode) []WordBlock {
+	if !n.is(nsOdfDraw, "frame") {
+		return e.blocks(n)
+	}
+	var blocks []WordBlock
+	for _, c := range n.nodes {
+		switch {
+		case c.is(nsOdfDraw, "text-box"):
+			blocks = append(blocks, e.blocks(c)...)
+		case c.is(nsOdfTable, "table"):
+			if table := e.table(c); table != nil {
+				blocks = append(blocks, WordBlock{Table: table})
+			}
+		}
+	}
+	return blocks
+}
@@ -0,0 +1,229 @@
+package office
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ods 的单元格值写在 office:value-type 和对应的值属性中，显示文本在单元格内的段落。
+// 整行整列的空格式常以 number-*-repeated 写成极大的重复数，空行空列只在后面有内容时展开。
+
+func parseOds(filePath string, opts ExcelOptions) (*Workbook, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	body, styles, err := readOdfBody(pkg, "spreadsheet")
+	if err != nil {
+		return nil, errors.New("读取文件内容失败！")
+	}
+	book := &Workbook{}
+	for _, tbl := range body.nodes {
+		if !tbl.is(nsOdfTable, "table") {
+			continue
+		}
+		grid, merges := odsGrid(tbl)
+		if opts.FillMergedDown || opts.FillMergedAcross {
+			fillMerged(grid, merges, opts)
+		}
+		st := styles.get("table", tbl.attr(nsOdfTable, "style-name"))
+		book.Sheets = append(book.Sheets, gridSheet(tbl.attr(nsOdfTable, "name"), st != nil && st.hidden, grid, opts, false))
+	}
+	return book, nil
+}
+
+// 工作表的单元格和合并区域
+func odsGrid(tbl *odfNode) ([][]Cell, []mergeRange) {
+	var grid [][]Cell
+	var merges []mergeRange
+	pending := 0 // 尚未展开的空行
+	for _, tr := range odsRows(tbl) {
+		repeat := tr.intAttr(nsOdfTable, "number-rows-repeated", 1)
+		cells, spans := odsRow(tr)
+		if isEmptyRow(cells) {
+			pending += repeat
+			continue
+		}
+		for ; pending > 0 && len(grid) < odfMaxRows; pending-- {
+			grid = append(grid, nil)
+		}
+		pending = 0
+		for k := 0; k < repeat && len(grid) < odfMaxRows; k++ {
+			for _, m := range spans {
+				m.row, m.lastRow = len(grid), len(grid)+m.lastRow
+				merges = append(merges, m)
+			}
+			grid = append(grid, append([]Cell(nil), cells...))
+		}
+	}
+	return grid, merges
+}
+
+// 表格中的行，不展开重复行
+func odsRows(n *odfNode) []*odfNode {
+	var rows []*odfNode
+	for _, c := range n.nodes {
+		switch {
+		case c.is(nsOdfTable, "table-row"):
+			rows = append(rows, c)
+		case c.is(nsOdfTable, "table-header-rows"), c.is(nsOdfTable, "table-row-group"), c.is(nsOdfTable, "table-rows"):
+			rows = append(rows, odsRows(c)...)
+		}
+	}
+	return rows
+}
+
+// 一行的单元格，以及从该行开始的合并区域（行号相对该行）
+func odsRow(tr *odfNode) ([]Cell, []mergeRange) {
+	var cells []Cell
+	var spans []mergeRange
+	pending := 0 // 尚未展开的空单元格
+	for _, tc := range tr.nodes {
+		if !tc.is(nsOdfTable, "table-cell") && !tc.is(nsOdfTable, "covered-table-cell") {
+			continue
+		}
+		repeat := tc.intAttr(nsOdfTable, "number-columns-repeated", 1)
+		cell := Cell{Type: CellTypeEmpty}
+		if tc.is(nsOdfTable, "table-cell") {
+			cell = odsCell(tc)
+		}
+		if cell.Type == CellTypeEmpty && cell.Formula == "" {
+			pending += repeat
+			continue
+		}
+		for ; pending > 0 && len(cells) < odfMaxCols; pending-- {
+			cells = append(cells, Cell{Type: CellTypeEmpty})
+		}
+		pending = 0
+		colSpan := tc.intAttr(nsOdfTable, "number-columns-spanned", 1)
+		rowSpan := tc.intAttr(nsOdfTable, "number-rows-spanned", 1)
+		for k := 0; k < repeat && len(cells) < odfMaxCols; k++ {
+			if colSpan > 1 || rowSpan > 1 {
+				spans = append(spans, mergeRange{col: len(cells), lastRow: rowSpan - 1, lastCol: len(cells) + colSpan - 1})
+			}
+			cells = append(cells, cell)
+		}
+	}
+	return cells, spans
+}
+
+func odsCell(tc *odfNode) Cell {
+	var lines []string
+	for _, p := range tc.nodes {
+		if p.is(nsOdfText, "p") || p.is(nsOdfText, "h") {
+			lines = append(lines, odfText(p))
+		}
+	}
+	text := strings.Join(lines, "\n")
+
+	c := Cell{Type: CellTypeEmpty}
+	// 公式带命名空间前缀，如 of:=SUM([.A1:.A3])
+	formula := tc.attr(nsOdfTable, "formula")
+	if i := strings.Index(formula, "="); i >= 0 {
+		formula = formula[i+1:]
+	}
+	c.Formula = formula
+
+	switch tc.attr(nsOdfOffice, "value-type") {
+	case "float", "percentage", "currency":
+		v := tc.attr(nsOdfOffice, "value")
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			break
+		}
+		c.Type, c.Value, c.Number, c.Text = CellTypeNumber, v, n, text
+		if c.Text == "" {
+			c.Text = strconv.FormatFloat(n, 'f', -1, 64)
+		}
+		return c
+	case "date":
+		v := tc.attr(nsOdfOffice, "date-value")
+		t := parseIsoDate(v)
+		if t == nil {
+			break
+		}
+		c.Type, c.Value, c.Time, c.Number, c.Text = CellTypeDate, v, t, excelTime(*t), formatDate(*t, "")
+		return c
+	case "time":
+		v := tc.attr(nsOdfOffice, "time-value")
+		d, ok := odfDuration(v)
+		if !ok {
+			break
+		}
+		// 与 excel 一致，时间为 1899-12-30 当天的时刻，超过一天的时长保留显示文本
+		t := time.Date(1899, 12, 30, 0, 0, 0, 0, time.Local).Add(d)
+		c.Type, c.Value, c.Time, c.Number, c.Text = CellTypeDate, v, &t, d.Hours()/24, t.Format("15:04:05")
+		if d < 0 || d >= 24*time.Hour {
+			c.Text = text
+		}
+		return c
+	case "boolean":
+		c.Type, c.Bool = CellTypeBool, tc.attr(nsOdfOffice, "boolean-value") == "true"
+		c.Text, c.Value = strings.ToUpper(strconv.FormatBool(c.Bool)), "0"
+		if c.Bool {
+			c.Value = "1"
+		}
+		return c
+	}
+	if text == "" {
+		return c
+	}
+	// 没有值类型的公式结果为错误，如 #DIV/0! 或 Err:502
+	if c.Formula != "" && tc.attr(nsOdfOffice, "value-type") == "" && (strings.HasPrefix(text, "#") || strings.HasPrefix(text, "Err:")) {
+		c.Type, c.Text, c.Value = CellTypeError, text, text
+		return c
+	}
+	c.Type, c.Text, c.Value = CellTypeString, text, text
+	return c
+}
+
+// ISO 8601 时长，如 PT13H30M00S、P1DT2H
+func odfDuration(v string) (time.Duration, bool) {
+	neg := strings.HasPrefix(v, "-")
+	v = strings.TrimPrefix(v, "-")
+	if !strings.HasPrefix(v, "P") {
+		return 0, false
+	}
+	var d time.Duration
+	num, inTime := "", false
+	for _, r := range v[1:] {
+		if r == 'T' {
+			inTime = true
+			continue
+		}
+		if r >= '0' && r <= '9' || r == '.' {
+			num += string(r)
+			continue
+		}
+		n, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return 0, false
+		}
+		var unit time.Duration
+		switch {
+		case r == 'D' && !inTime:
+			unit = 24 * time.Hour
+		case r == 'H' && inTime:
+			unit = time.Hour
+		case r == 'M' && inTime:
+			unit = time.Minute
+		case r == 'S' && inTime:
+			unit = time.Second
+		default:
+			return 0, false
+		}
+		d += time.Duration(n * float64(unit))
+		num = ""
+	}
+	if num != "" {
+		return 0, false
+	}
+	if neg {
+		d = -d
+	}
+	return d, true
+}
@@ -1,309 +1,171 @@
 package office
 
-import (
-	"baliance.com/gooxml/document"
-	"baliance.com/gooxml/presentation"
-	"errors"
-	"github.com/tealeg/xlsx"
-	"io/ioutil"
-	"log"
-	"os"
-	"strings"
-)
-
-type excelRes struct {
-	Question string `json:"question"` // 问题
-	Answer   string `json:"answer"`   // 答案
+// 问答
+type ExcelRes struct {
+	Question string `json:"question" xlsx:"header=问题|题目|question,index=1,required,width=50,hint=每行一个问题"` // 问题
+	Answer   string `json:"answer" xlsx:"header=答案|回答|answer,index=2,required,width=80,hint=问题对应的答案"`    // 答案
 }
 
-// word文件转文字
+// word文件转文字，段落之间换行，列表项带编号
 func WordToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
-	suffix, err := getSuffix(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("获取前缀失败！")
-	}
-	size, err := countSize(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("计算文件大小失败！")
-	}
-
-	doc, err := document.Open(filePath)
+	doc, suffix, size, err := WordToStructure(filePath)
 	if err != nil {
-		return "", "", 0, errors.New("打开文件失败！")
-	}
-
-	// 遍历文档中的每个段落
-	text := ""
-	for _, p := range doc.Paragraphs() {
-		// 遍历段落中的每个 Run
-		for _, r := range p.Runs() {
-			// 打印 Run 的文本
-			text += r.Text()
-		}
+		return "", "", 0, err
 	}
-
-	return text, suffix, size, nil
+	return doc.Text(), suffix, size, nil
 }
 
 // word地址文件转文字
 func WordUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
-	suffix, err := getSuffix(url)
+	doc, suffix, size, err := WordUrlToStructure(url)
 	if err != nil {
-		return "", "", 0, errors.New("获取前缀失败！")
-	}
-
-	filePath, err := saveFile(url, suffix)
-	if err != nil {
-		return "", "", 0, errors.New("文件保存在本地失败！")
-	}
-
-	size, err := countSize(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("计算文件大小失败！")
-	}
-
-	defer os.Remove(filePath)
-
-	doc, err := document.Open(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("打开文件失败！")
-	}
-
-	// 遍历文档中的每个段落
-	text := ""
-	for _, p := range doc.Paragraphs() {
-		// 遍历段落中的每个 Run
-		for _, r := range p.Runs() {
-			// 打印 Run 的文本
-			text += r.Text()
-		}
+		return "", "", 0, err
 	}
-	return text, suffix, size, nil
+	return doc.Text(), suffix, size, nil
 }
 
-// excel文件转文字
-func ExcelToContent(filePath string) (word []excelRes, fileSuffix string, FileSize int, err error) {
-	var list []excelRes
-	suffix, err := getSuffix(filePath)
+// excel文件转问答列表，按表头“问题”“答案”取值，没有这两列时取前两列
+func ExcelToContent(filePath string) (word []ExcelRes, fileSuffix string, FileSize int, err error) {
+	book, suffix, size, err := ExcelToWorkbook(filePath)
 	if err != nil {
-		return list, "", 0, errors.New("获取前缀失败！")
+		return nil, "", 0, err
 	}
-	size, err := countSize(filePath)
+	list, err := book.questions()
 	if err != nil {
-		return list, "", 0, errors.New("计算文件大小失败！")
-	}
-	xlFile, err := xlsx.OpenFile(filePath)
-	if err != nil {
-		return list, "", 0, errors.New("打开文件失败！")
-	}
-
-	for _, sheet := range xlFile.Sheets {
-		for k, row := range sheet.Rows {
-			if k == 0 {
-				continue
-			}
-			per := excelRes{}
-			if len(row.Cells) == 0 {
-    				continue
-   			}
-			per.Question = row.Cells[0].String()
-			per.Answer = row.Cells[1].String()
-			list = append(list, per)
-		}
+		return nil, "", 0, err
 	}
 	return list, suffix, size, nil
 }
 
-// excel地址文件转文字
-func ExcelUrlToContent(url string) (word []excelRes, fileSuffix string, FileSize int, err error) {
-	var list []excelRes
-	suffix, err := getSuffix(url)
-	if err != nil {
-		return list, "", 0, errors.New("获取前缀失败！")
-	}
-
-	filePath, err := saveFile(url, suffix)
+// excel地址文件转问答列表
+func ExcelUrlToContent(url string) (word []ExcelRes, fileSuffix string, FileSize int, err error) {
+	book, suffix, size, err := ExcelUrlToWorkbook(url)
 	if err != nil {
-		return list, "", 0, errors.New("文件保存在本地失败！")
+		return nil, "", 0, err
 	}
-
-	size, err := countSize(filePath)
-	if err != nil {
-		return list, "", 0, errors.New("计算文件大小失败！")
-	}
-
-	xlFile, err := xlsx.OpenFile(filePath)
+	list, err := book.questions()
 	if err != nil {
-		return list, "", 0, errors.New("打开文件失败！")
+		return nil, "", 0, err
 	}
+	return list, suffix, size, nil
+}
 
-	defer os.Remove(filePath)
+// 问答列映射
+var ExcelQaColumns = []Column{
+	{Field: "question", Headers: []string{"问题", "题目", "question"}, Index: 1},
+	{Field: "answer", Headers: []string{"答案", "回答", "answer"}, Index: 2},
+}
 
-	for _, sheet := range xlFile.Sheets {
-		for k, row := range sheet.Rows {
-			if k == 0 {
-				continue
-			}
-			per := excelRes{}
-			if len(row.Cells) == 0 {
-    				continue
-   			}
-			per.Question = row.Cells[0].String()
-			per.Answer = row.Cells[1].String()
-			list = append(list, per)
+func (w *Workbook) questions() ([]ExcelRes, error) {
+	var list []ExcelRes
+	for i := range w.Sheets {
+		records, err := w.Sheets[i].Records(ExcelQaColumns)
+		if err != nil {
+			return nil, err
+		}
+		for _, rec := range records {
+			list = append(list, ExcelRes{Question: rec.Get("question"), Answer: rec.Get("answer")})
 		}
 	}
-
-	return list, suffix, size, nil
+	return list, nil
 }
 
-// ppt文件转文字
+// ppt文件转文字，幻灯片之间空一行，ppt、odp格式按文件头识别
 func PptToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
-	suffix, err := getSuffix(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("获取前缀失败！")
-	}
-	size, err := countSize(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("计算文件大小失败！")
-	}
-
-	ppt, err := presentation.Open(filePath)
+	pres, suffix, size, err := PptToStructure(filePath)
 	if err != nil {
-		return "", "", 0, errors.New("打开文件失败！")
-	}
-
-	var text string
-	for _, slide := range ppt.Slides() {
-		//所有的控件
-		for _, choice := range slide.X().CSld.SpTree.Choice {
-			if choice.Sp == nil {
-				continue
-			}
-			//一个文本框或一个控件
-			for _, sp := range choice.Sp {
-				if sp.TxBody == nil {
-					continue
-				}
-				//数据
-				for _, p := range sp.TxBody.P {
-					textrun := p.EG_TextRun
-					//所有的EG_TextRun中的数据组合起来是一段
-					for _, run := range textrun {
-						if run.R != nil {
-							text += run.R.T
-						}
-					}
-					if len(text) == 0 {
-						continue
-					}
-				}
-			}
-		}
+		return "", "", 0, err
 	}
-
-	return text, suffix, size, nil
+	return pres.Text(), suffix, size, nil
 }
 
 // ppt地址文件转文字
 func PptUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
-	suffix, err := getSuffix(url)
+	pres, suffix, size, err := PptUrlToStructure(url)
 	if err != nil {
-		return "", "", 0, errors.New("获取前缀失败！")
+		return "", "", 0, err
 	}
+	return pres.Text(), suffix, size, nil
+}
 
-	filePath, err := saveFile(url, suffix)
-	if err != nil {
-		return "", "", 0, errors.New("文件保存在本地失败！")
-	}
+// txt文件转文字，编码自动检测，各行以空格连接
+func TxtToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
+	return TxtToContentWithOptions(filePath, TxtOptions{})
+}
 
-	size, err := countSize(filePath)
-	if err != nil {
-		return "", "", 0, errors.New("计算文件大小失败！")
-	}
+// txt地址文件转文字
+func TxtUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
+	return TxtUrlToContentWithOptions(url, TxtOptions{})
+}
 
-	ppt, err := presentation.Open(filePath)
+// html文件转文字，编码按 meta 声明识别
+func HtmlToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := HtmlToStructure(filePath)
 	if err != nil {
-		return "", "", 0, errors.New("打开文件失败！")
-	}
-
-	var text string
-	for _, slide := range ppt.Slides() {
-		//所有的控件
-		for _, choice := range slide.X().CSld.SpTree.Choice {
-			if choice.Sp == nil {
-				continue
-			}
-			//一个文本框或一个控件
-			for _, sp := range choice.Sp {
-				if sp.TxBody == nil {
-					continue
-				}
-				//数据
-				for _, p := range sp.TxBody.P {
-					textrun := p.EG_TextRun
-					//所有的EG_TextRun中的数据组合起来是一段
-					for _, run := range textrun {
-						if run.R != nil {
-							text += run.R.T
-						}
-					}
-					if len(text) == 0 {
-						continue
-					}
-				}
-			}
-		}
+		return "", "", 0, err
 	}
-
-	return text, suffix, size, nil
+	return doc.Text(), suffix, size, nil
 }
 
-// txt文件转文字
-func TxtToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
-	suffix, err := getSuffix(filePath)
+// html地址文件转文字
+func HtmlUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := HtmlUrlToStructure(url)
 	if err != nil {
-		return "", "", 0, errors.New("获取前缀失败！")
+		return "", "", 0, err
 	}
-	size, err := countSize(filePath)
+	return doc.Text(), suffix, size, nil
+}
+
+// markdown文件转文字，去掉标记
+func MarkdownToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := MarkdownToStructure(filePath)
 	if err != nil {
-		return "", "", 0, errors.New("计算文件大小失败！")
+		return "", "", 0, err
 	}
-	content, err := ioutil.ReadFile(filePath)
+	return doc.Text(), suffix, size, nil
+}
+
+// markdown地址文件转文字
+func MarkdownUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := MarkdownUrlToStructure(url)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", 0, err
 	}
-	text := string(content)
-	text = strings.Replace(text, "\n", " ", -1)
-
-	return text, suffix, size, nil
+	return doc.Text(), suffix, size, nil
 }
 
-// txt地址文件转文字
-func TxtUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
-	suffix, err := getSuffix(url)
+// rtf文件转文字
+func RtfToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := RtfToStructure(filePath)
 	if err != nil {
 		return "", "", 0, err
 	}
+	return doc.Text(), suffix, size, nil
+}
 
-	filePath, err := saveFile(url, suffix)
+// rtf地址文件转文字
+func RtfUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := RtfUrlToStructure(url)
 	if err != nil {
-		return "", "", 0, errors.New("文件保存在本地失败！")
+		return "", "", 0, err
 	}
+	return doc.Text(), suffix, size, nil
+}
 
-	size, err := countSize(filePath)
+// epub文件转文字，按阅读顺序，每章以章节名开头
+func EpubToContent(filePath string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := EpubToStructure(filePath)
 	if err != nil {
-		return "", "", 0, errors.New("计算文件大小失败！")
+		return "", "", 0, err
 	}
+	return doc.Text(), suffix, size, nil
+}
 
-	defer os.Remove(filePath)
-
-	content, err := ioutil.ReadFile(filePath)
+// epub地址文件转文字
+func EpubUrlToContent(url string) (word string, fileSuffix string, FileSize int, err error) {
+	doc, suffix, size, err := EpubUrlToStructure(url)
 	if err != nil {
-		log.Fatal(err)
+		return "", "", 0, err
 	}
-	text := string(content)
-	text = strings.Replace(text, "\n", " ", -1)
-
-	return text, suffix, size, nil
+	return doc.Text(), suffix, size, nil
 }
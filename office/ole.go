@@ -0,0 +1,86 @@
+package office
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/richardlehane/mscfb"
+)
+
+// OLE 复合文档（doc/xls/ppt）的文件头
+var oleMagic = []byte{0xd0, 0xcf, 0x11, 0xe0, 0xa1, 0xb1, 0x1a, 0xe1}
+
+var errOleEncrypted = errors.New("文件已加密，无法读取！")
+
+// 是否为 OLE 复合文档，按文件头判断，不看后缀
+func isOleFile(filePath string) bool {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, len(oleMagic))
+	if _, err := io.ReadFull(f, head); err != nil {
+		return false
+	}
+	return bytes.Equal(head, oleMagic)
+}
+
+// 读取复合文档根目录下的指定流，以传入的名称为键，不存在的流不出现在结果中
+func readOleStreams(filePath string, names ...string) (map[string][]byte, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := mscfb.New(f)
+	if err != nil {
+		return nil, err
+	}
+	streams := map[string][]byte{}
+	for entry, err := r.Next(); err == nil; entry, err = r.Next() {
+		name := matchFold(names, entry.Name)
+		if len(entry.Path) > 0 || name == "" {
+			continue
+		}
+		buf := make([]byte, entry.Size)
+		if _, err := io.ReadFull(entry, buf); err != nil {
+			return nil, err
+		}
+		streams[name] = buf
+	}
+	return streams, nil
+}
+
+// 流名不区分大小写，返回 list 中匹配的名称
+func matchFold(list []string, s string) string {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return v
+		}
+	}
+	return ""
+}
+
+// 复合文档根目录下的流名称
+func oleStreamNames(filePath string) ([]string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	r, err := mscfb.New(f)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for entry, err := r.Next(); err == nil; entry, err = r.Next() {
+		if len(entry.Path) == 0 {
+			names = append(names, entry.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,159 @@
+package office
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"mime"
+	"path"
+	"strings"
+)
+
+// 关系类型后缀，完整类型带有 officeDocument/2006/relationships 等前缀
+const (
+	relOfficeDocument = "/officeDocument"
+	relFootnotes      = "/footnotes"
+	relEndnotes       = "/endnotes"
+	relComments       = "/comments"
+)
+
+var errPartNotFound = errors.New("part not found")
+
+// OOXML（docx/xlsx/pptx）zip 包，按路径读取其中的部件
+type ooxmlPackage struct {
+	zr    *zip.ReadCloser
+	files map[string]*zip.File
+	types map[string]string // [Content_Types].xml，部件路径或 "."+扩展名 -> 内容类型
+}
+
+// 包内关系
+type ooxmlRel struct {
+	Id         string `xml:"Id,attr"`
+	Type       string `xml:"Type,attr"`
+	Target     string `xml:"Target,attr"`
+	TargetMode string `xml:"TargetMode,attr"`
+}
+
+func openOoxml(filePath string) (*ooxmlPackage, error) {
+	zr, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, err
+	}
+	p := &ooxmlPackage{zr: zr, files: map[string]*zip.File{}}
+	for _, f := range zr.File {
+		p.files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	return p, nil
+}
+
+func (p *ooxmlPackage) Close() error {
+	return p.zr.Close()
+}
+
+func (p *ooxmlPackage) has(name string) bool {
+	_, ok := p.files[name]
+	return ok
+}
+
+func (p *ooxmlPackage) open(name string) (io.ReadCloser, error) {
+	f, ok := p.files[name]
+	if !ok {
+		return nil, errPartNotFound
+	}
+	return f.Open()
+}
+
+func (p *ooxmlPackage) read(name string) ([]byte, error) {
+	rc, err := p.open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// 将部件反序列化到 v
+func (p *ooxmlPackage) decode(name string, v interface{}) error {
+	rc, err := p.open(name)
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// 部件的关系列表，Target 已解析为包内绝对路径（外部链接除外）
+func (p *ooxmlPackage) rels(part string) []ooxmlRel {
+	dir, file := path.Split(part)
+	var res struct {
+		Rels []ooxmlRel `xml:"Relationship"`
+	}
+	if err := p.decode(path.Join(dir, "_rels", file+".rels"), &res); err != nil {
+		return nil
+	}
+	for i, r := range res.Rels {
+		if r.TargetMode == "External" {
+			continue
+		}
+		if strings.HasPrefix(r.Target, "/") {
+			res.Rels[i].Target = strings.TrimPrefix(r.Target, "/")
+		} else {
+			res.Rels[i].Target = path.Join(dir, r.Target)
+		}
+	}
+	return res.Rels
+}
+
+// 按关系类型查找目标部件
+func (p *ooxmlPackage) relTarget(part string, relType string) string {
+	for _, r := range p.rels(part) {
+		if strings.HasSuffix(r.Type, relType) && r.TargetMode != "External" {
+			return r.Target
+		}
+	}
+	return ""
+}
+
+// 主文档部件，如 word/document.xml
+func (p *ooxmlPackage) mainPart(fallback string) string {
+	if target := p.relTarget("", relOfficeDocument); target != "" && p.has(target) {
+		return target
+	}
+	return fallback
+}
+
+// 部件的内容类型，先查 Override，再按扩展名查 Default
+func (p *ooxmlPackage) contentType(part string) string {
+	if p.types == nil {
+		p.types = map[string]string{}
+		var types struct {
+			Defaults []struct {
+				Extension   string `xml:"Extension,attr"`
+				ContentType string `xml:"ContentType,attr"`
+			} `xml:"Default"`
+			Overrides []struct {
+				PartName    string `xml:"PartName,attr"`
+				ContentType string `xml:"ContentType,attr"`
+			} `xml:"Override"`
+		}
+		if p.decode("[Content_Types].xml", &types) == nil {
+			for _, d := range types.Defaults {
+				p.types["."+strings.ToLower(d.Extension)] = d.ContentType
+			}
+			for _, o := range types.Overrides {
+				p.types[strings.TrimPrefix(o.PartName, "/")] = o.ContentType
+			}
+		}
+	}
+	if t, ok := p.types[part]; ok {
+		return t
+	}
+	ext := strings.ToLower(path.Ext(part))
+	if t, ok := p.types[ext]; ok {
+		return t
+	}
+	// 部分工具生成的文件缺少图片扩展名的 Default
+	t, _, _ := mime.ParseMediaType(mime.TypeByExtension(ext))
+	return t
+}
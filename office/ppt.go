@@ -0,0 +1,153 @@
+package office
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// ppt结构化内容
+type Presentation struct {
+	Slides []Slide `json:"slides"`
+}
+
+// 幻灯片
+type Slide struct {
+	Number int         `json:"number"`           // 幻灯片编号，从 1 开始
+	Title  string      `json:"title,omitempty"`  // 标题占位符的文字
+	Body   []WordBlock `json:"body,omitempty"`   // 按形状顺序排列的段落和表格，含组合中的形状
+	Notes  []string    `json:"notes,omitempty"`  // 演讲者备注，每段一项
+	Hidden bool        `json:"hidden,omitempty"` // 放映时隐藏
+	Layout string      `json:"layout,omitempty"` // 版式名称，odp为母版名称，ppt格式为空
+}
+
+// ppt文件转结构化内容，ppt、odp格式按文件头识别
+func PptToStructure(filePath string) (pres *Presentation, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	pres, err = parsePpt(filePath)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return pres, suffix, size, nil
+}
+
+// ppt地址文件转结构化内容
+func PptUrlToStructure(url string) (pres *Presentation, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return nil, "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	pres, err = parsePpt(filePath)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return pres, suffix, size, nil
+}
+
+// 纯文本，幻灯片之间以空行分隔
+func (p *Presentation) Text() string {
+	parts := make([]string, 0, len(p.Slides))
+	for _, s := range p.Slides {
+		if text := s.Text(); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Markdown，每张幻灯片一个二级标题，幻灯片之间以分隔线分隔
+func (p *Presentation) Markdown() string {
+	parts := make([]string, 0, len(p.Slides))
+	for _, s := range p.Slides {
+		parts = append(parts, s.Markdown())
+	}
+	return strings.Join(parts, "\n\n---\n\n")
+}
+
+// 单张幻灯片的文字：标题、正文、备注依次换行，段落内不含空行
+func (s Slide) Text() string {
+	var lines []string
+	if s.Title != "" {
+		lines = append(lines, s.Title)
+	}
+	if len(s.Body) > 0 {
+		lines = append(lines, blocksText(s.Body))
+	}
+	if len(s.Notes) > 0 {
+		lines = append(lines, "备注："+strings.Join(s.Notes, "\n"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// 单张幻灯片的 Markdown，标题行带编号，备注以引用块列出
+func (s Slide) Markdown() string {
+	heading := fmt.Sprintf("## 第 %d 页", s.Number)
+	if s.Title != "" {
+		heading += "：" + markdownEscape(strings.Join(strings.Fields(s.Title), " "))
+	}
+	if s.Hidden {
+		heading += "（隐藏）"
+	}
+	sections := []string{heading}
+	if len(s.Body) > 0 {
+		sections = append(sections, blocksMarkdown(s.Body))
+	}
+	if len(s.Notes) > 0 {
+		lines := make([]string, 0, len(s.Notes)+1)
+		lines = append(lines, "> **备注**")
+		for _, n := range s.Notes {
+			lines = append(lines, "> "+markdownEscape(n))
+		}
+		sections = append(sections, strings.Join(lines, "\n>\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+func parsePpt(filePath string) (*Presentation, error) {
+	if isOleFile(filePath) {
+		slides, err := parseLegacyPpt(filePath)
+		if err != nil {
+			return nil, err
+		}
+		pres := &Presentation{}
+		for i, s := range slides {
+			slide := Slide{Number: i + 1, Title: s.title, Notes: s.notes, Hidden: s.hidden}
+			for _, text := range s.body {
+				slide.Body = append(slide.Body, WordBlock{Paragraph: &WordParagraph{Text: text, Spans: []WordSpan{{Text: text}}}})
+			}
+			pres.Slides = append(pres.Slides, slide)
+		}
+		return pres, nil
+	}
+	if odfFileFormat(filePath) == FormatOdp {
+		return parseOdp(filePath)
+	}
+	return parsePptx(filePath)
+}
@@ -0,0 +1,318 @@
+package office
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+// pptx 按 presentation.xml 中的幻灯片列表顺序读取各幻灯片部件，
+// 遍历形状树中的文本框、组合、表格，备注取备注页的正文占位符。
+
+const (
+	relSlide       = "/slide"
+	relSlideLayout = "/slideLayout"
+	relNotesSlide  = "/notesSlide"
+)
+
+// 不作为正文的占位符：日期、页脚、幻灯片编号
+var pptxSkipPlaceholders = map[string]bool{"dt": true, "ftr": true, "sldNum": true, "hdr": true}
+
+type pptxShape struct {
+	NvSpPr struct {
+		NvPr struct {
+			Ph *struct {
+				Type string `xml:"type,attr"`
+			} `xml:"ph"`
+		} `xml:"nvPr"`
+	} `xml:"nvSpPr"`
+	TxBody *pptxTextBody `xml:"txBody"`
+}
+
+type pptxTextBody struct {
+	P []pptxParagraph `xml:"p"`
+}
+
+type pptxParagraph struct {
+	PPr struct {
+		Lvl int `xml:"lvl,attr"`
+	} `xml:"pPr"`
+	// 文字、换行、域按顺序出现
+	Runs []pptxRun `xml:",any"`
+}
+
+type pptxRun struct {
+	XMLName xml.Name
+	RPr     struct {
+		B string `xml:"b,attr"`
+		I string `xml:"i,attr"`
+	} `xml:"rPr"`
+	T string `xml:"t"`
+}
+
+type pptxFrame struct {
+	Tbl *pptxTable `xml:"graphic>graphicData>tbl"`
+}
+
+type pptxTable struct {
+	Cols []struct{} `xml:"tblGrid>gridCol"`
+	Rows []struct {
+		Cells []pptxTableCell `xml:"tc"`
+	} `xml:"tr"`
+}
+
+type pptxTableCell struct {
+	GridSpan int           `xml:"gridSpan,attr"`
+	RowSpan  int           `xml:"rowSpan,attr"`
+	HMerge   bool          `xml:"hMerge,attr"`
+	VMerge   bool          `xml:"vMerge,attr"`
+	TxBody   *pptxTextBody `xml:"txBody"`
+}
+
+type pptxPicture struct {
+	Blip struct {
+		Embed string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships embed,attr"`
+	} `xml:"blipFill>blip"`
+}
+
+// 幻灯片中的一个形状读取结果，图片只有 image
+type pptxItem struct {
+	placeholder string
+	isTitle     bool
+	blocks      []WordBlock
+	image       string // 图片的关系ID
+}
+
+func parsePptx(filePath string) (*Presentation, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	main := pkg.mainPart("ppt/presentation.xml")
+	var doc struct {
+		Ids []struct {
+			Rid string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err := pkg.decode(main, &doc); err != nil {
+		return nil, errors.New("读取演示文稿失败！")
+	}
+	targets := map[string]string{}
+	for _, r := range pkg.rels(main) {
+		if strings.HasSuffix(r.Type, relSlide) {
+			targets[r.Id] = r.Target
+		}
+	}
+
+	pres := &Presentation{}
+	layouts := map[string]string{} // 版式部件 -> 版式名称
+	for _, id := range doc.Ids {
+		part, ok := targets[id.Rid]
+		if !ok {
+			continue
+		}
+		slide, err := readPptxSlide(pkg, part, layouts)
+		if err != nil {
+			return nil, errors.New("读取幻灯片失败！")
+		}
+		slide.Number = len(pres.Slides) + 1
+		pres.Slides = append(pres.Slides, slide)
+	}
+	return pres, nil
+}
+
+func readPptxSlide(pkg *ooxmlPackage, part string, layouts map[string]string) (Slide, error) {
+	var slide Slide
+	items, root, err := readPptxShapes(pkg, part)
+	if err != nil {
+		return slide, err
+	}
+	slide.Hidden = xmlAttr(root, "show") == "0"
+	if layoutPart := pkg.relTarget(part, relSlideLayout); layoutPart != "" {
+		name, ok := layouts[layoutPart]
+		if !ok {
+			var layout struct {
+				CSld struct {
+					Name string `xml:"name,attr"`
+				} `xml:"cSld"`
+			}
+			if pkg.decode(layoutPart, &layout) == nil {
+				name = layout.CSld.Name
+			}
+			layouts[layoutPart] = name
+		}
+		slide.Layout = name
+	}
+	for _, item := range items {
+		if item.isTitle && slide.Title == "" {
+			slide.Title = blocksText(item.blocks)
+			continue
+		}
+		slide.Body = append(slide.Body, item.blocks...)
+	}
+
+	if notesPart := pkg.relTarget(part, relNotesSlide); notesPart != "" {
+		items, _, err := readPptxShapes(pkg, notesPart)
+		if err != nil {
+			return slide, err
+		}
+		for _, item := range items {
+			if item.placeholder != "body" {
+				continue
+			}
+			for _, b := range item.blocks {
+				if b.Paragraph != nil {
+					slide.Notes = append(slide.Notes, b.Paragraph.Text)
+				}
+			}
+		}
+	}
+	return slide, nil
+}
+
+// 按文档顺序读取形状树中所有形状的文字，组合递归展开，同时返回根元素
+func readPptxShapes(pkg *ooxmlPackage, part string) ([]pptxItem, xml.StartElement, error) {
+	var root xml.StartElement
+	rc, err := pkg.open(part)
+	if err != nil {
+		return nil, root, err
+	}
+	defer rc.Close()
+
+	var items []pptxItem
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			return items, root, nil
+		}
+		if err != nil {
+			return nil, root, err
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if root.Name.Local == "" {
+			root = start.Copy()
+		}
+		switch {
+		case start.Name.Space == nsMc && start.Name.Local == "Fallback":
+			// 兼容旧版本的重复内容，Choice 中已经读取过
+			if err := d.Skip(); err != nil {
+				return nil, root, err
+			}
+		case start.Name.Local == "sp":
+			var sp pptxShape
+			if err := d.DecodeElement(&sp, &start); err != nil {
+				return nil, root, err
+			}
+			if item, ok := pptxShapeItem(sp); ok {
+				items = append(items, item)
+			}
+		case start.Name.Local == "graphicFrame":
+			var frame pptxFrame
+			if err := d.DecodeElement(&frame, &start); err != nil {
+				return nil, root, err
+			}
+			if frame.Tbl != nil {
+				items = append(items, pptxItem{blocks: []WordBlock{{Table: pptxTableBlock(frame.Tbl)}}})
+			}
+		case start.Name.Local == "pic":
+			var pic pptxPicture
+			if err := d.DecodeElement(&pic, &start); err != nil {
+				return nil, root, err
+			}
+			if pic.Blip.Embed != "" {
+				items = append(items, pptxItem{image: pic.Blip.Embed})
+			}
+		}
+	}
+}
+
+func pptxShapeItem(sp pptxShape) (pptxItem, bool) {
+	var item pptxItem
+	if ph := sp.NvSpPr.NvPr.Ph; ph != nil {
+		item.placeholder = ph.Type
+		// 未指定类型的占位符为正文
+		if item.placeholder == "" {
+			item.placeholder = "body"
+		}
+		if pptxSkipPlaceholders[item.placeholder] {
+			return item, false
+		}
+		item.isTitle = item.placeholder == "title" || item.placeholder == "ctrTitle"
+	}
+	if sp.TxBody == nil {
+		return item, false
+	}
+	item.blocks = pptxParagraphs(sp.TxBody)
+	return item, len(item.blocks) > 0
+}
+
+// 文本框中的非空段落
+func pptxParagraphs(body *pptxTextBody) []WordBlock {
+	var blocks []WordBlock
+	for _, p := range body.P {
+		var spans []WordSpan
+		for _, r := range p.Runs {
+			switch r.XMLName.Local {
+			case "r", "fld":
+				spans = append(spans, WordSpan{Text: r.T, Bold: xmlBool(r.RPr.B), Italic: xmlBool(r.RPr.I)})
+			case "br":
+				spans = append(spans, WordSpan{Text: "\n"})
+			}
+		}
+		spans = mergeSpans(spans)
+		var sb strings.Builder
+		for _, s := range spans {
+			sb.WriteString(s.Text)
+		}
+		text := strings.TrimSpace(sb.String())
+		if text == "" {
+			continue
+		}
+		blocks = append(blocks, WordBlock{Paragraph: &WordParagraph{Text: text, ListLevel: p.PPr.Lvl, Spans: spans}})
+	}
+	return blocks
+}
+
+func xmlBool(v string) bool {
+	return v == "1" || v == "true"
+}
+
+// 表格转为与 word 相同的结构，横向合并的单元格并入左侧，纵向合并的单元格标记为 Merged
+func pptxTableBlock(tbl *pptxTable) *WordTable {
+	t := &WordTable{Cols: len(tbl.Cols)}
+	for _, tr := range tbl.Rows {
+		row := WordRow{}
+		for col, tc := range tr.Cells {
+			if tc.HMerge {
+				continue
+			}
+			cell := WordCell{Col: col, ColSpan: maxInt(tc.GridSpan, 1), RowSpan: maxInt(tc.RowSpan, 1)}
+			if tc.VMerge {
+				cell.Merged, cell.RowSpan = true, 0
+			} else if tc.TxBody != nil {
+				cell.Blocks = pptxParagraphs(tc.TxBody)
+				cell.Text = blocksText(cell.Blocks)
+			}
+			row.Cells = append(row.Cells, cell)
+		}
+		t.Rows = append(t.Rows, row)
+		if len(tr.Cells) > t.Cols {
+			t.Cols = len(tr.Cells)
+		}
+	}
+	return t
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,611 @@
+package office
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// rtf 由控制字、分组和文字组成，按控制字流逐个处理：分组保存字符格式，
+// 字体表给出各字体的字符集，\'hh 按当前字体的代码页解码，\uN 为 Unicode 字符。
+// 字体表、样式表和列表编号单独收集，图片、对象、页眉页脚、域代码等目标跳过。
+
+// rtf文件转结构化内容
+func RtfToStructure(filePath string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return fileToWord(filePath, parseRtf)
+}
+
+// rtf地址文件转结构化内容
+func RtfUrlToStructure(url string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return urlToWord(url, parseRtf)
+}
+
+func parseRtf(filePath string) (*WordDocument, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	if !bytes.HasPrefix(bytes.TrimPrefix(data, bomUTF8), []byte(`{\rtf`)) {
+		return nil, errors.New("不是有效的rtf文件！")
+	}
+	p := &rtfParser{data: data, ansicpg: 1252, deff: -1, fonts: map[int]int{}, styles: map[int]int{}}
+	p.state = rtfState{font: -1, uc: 1}
+	p.parse()
+	return &WordDocument{Blocks: p.blocks}, nil
+}
+
+// 跳过的目标
+var rtfSkipDests = map[string]bool{
+	"colortbl": true, "info": true, "pict": true, "object": true, "fldinst": true, "filetbl": true,
+	"header": true, "headerl": true, "headerr": true, "headerf": true,
+	"footer": true, "footerl": true, "footerr": true, "footerf": true,
+	"footnote": true, "annotation": true, "xe": true, "tc": true, "txe": true, "rxe": true,
+	"themedata": true, "colorschememapping": true, "latentstyles": true, "datastore": true,
+	"listtable": true, "listoverridetable": true, "revtbl": true, "rsidtbl": true, "generator": true,
+	"bkmkstart": true, "bkmkend": true, "nonshppict": true, "shpinst": true, "pn": true,
+}
+
+// 特殊字符
+var rtfSymbols = map[string]string{
+	"line": "\n", "tab": "\t", "emdash": "—", "endash": "–", "emspace": " ", "enspace": " ", "qmspace": " ",
+	"bullet": "•", "lquote": "‘", "rquote": "’", "ldblquote": "“", "rdblquote": "”",
+}
+
+// 分组内的状态，进入分组时保存，退出时恢复
+type rtfState struct {
+	bold   bool
+	italic bool
+	hidden bool
+	skip   bool   // 跳过的目标
+	dest   string // 字体表、样式表、列表编号，正文为空
+	entry  bool   // 样式表中的一条样式
+	font   int
+	uc     int // \uN 之后跳过的字符数
+}
+
+// 表格行定义中一个单元格的合并标记
+type rtfCellDef struct {
+	hMerge bool
+	vFirst bool
+	vMerge bool
+}
+
+type rtfParser struct {
+	data  []byte
+	pos   int
+	state rtfState
+	stack []rtfState
+
+	ansicpg   int
+	deff      int
+	fonts     map[int]int // 字体号对应的代码页
+	curFont   int         // 字体表中正在定义的字体
+	styles    map[int]int // 样式号对应的标题级别
+	styleNum  int
+	styleLvl  int
+	styleText strings.Builder
+	numText   strings.Builder
+
+	pending   []byte // 待解码的字节
+	skipChars int    // \uN 之后剩余要跳过的字符数
+	surrogate rune
+
+	blocks    []WordBlock
+	spans     []WordSpan
+	cur       strings.Builder
+	curBold   bool
+	curItalic bool
+	para      WordParagraph // 当前段落的标题、列表属性
+	numbering string        // 当前段落的列表编号
+
+	inTable bool
+	table   *WordTable
+	cells   [][]WordBlock // 当前行已结束的单元格
+	cell    []WordBlock   // 当前单元格已结束的段落
+	defs    []rtfCellDef
+	def     rtfCellDef
+	vOrigin map[int]cellPos // 各列纵向合并的起始单元格
+}
+
+func (p *rtfParser) parse() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch c {
+		case '{':
+			p.pos++
+			p.flushBytes()
+			p.skipChars = 0
+			p.stack = append(p.stack, p.state)
+			if p.state.dest == "stylesheet" && !p.state.entry {
+				p.state.entry = true
+				p.styleNum, p.styleLvl = 0, 0
+				p.styleText.Reset()
+			}
+		case '}':
+			p.pos++
+			p.flushBytes()
+			p.skipChars = 0
+			p.endGroup()
+		case '\\':
+			p.control()
+		case '\r', '\n':
+			p.pos++
+		default:
+			p.pos++
+			p.char(c)
+		}
+	}
+	p.flushBytes()
+	p.endParagraph()
+	if len(p.cells) > 0 {
+		p.endRow()
+	}
+	p.closeTable()
+}
+
+func (p *rtfParser) endGroup() {
+	if len(p.stack) == 0 {
+		return
+	}
+	closing := p.state
+	p.state = p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+	if closing.skip {
+		return
+	}
+	if closing.entry && !p.state.entry {
+		p.endStyle()
+	}
+	if closing.dest == "listtext" && p.state.dest != "listtext" {
+		p.numbering = strings.TrimSpace(p.numText.String())
+	}
+}
+
+// 样式名为标题或大纲级别不为正文的样式作为标题
+func (p *rtfParser) endStyle() {
+	name := strings.TrimSpace(p.styleText.String())
+	name = strings.TrimSpace(strings.Split(strings.TrimSuffix(name, ";"), ",")[0])
+	level := p.styleLvl
+	if m := headingName.FindStringSubmatch(name); m != nil {
+		level, _ = strconv.Atoi(m[2])
+	} else if strings.EqualFold(name, "title") {
+		level = 1
+	}
+	if level > 0 {
+		p.styles[p.styleNum] = level
+	}
+}
+
+func (p *rtfParser) control() {
+	d := p.data
+	p.pos++
+	if p.pos >= len(d) {
+		return
+	}
+	if c := d[p.pos]; !isAsciiLetter(c) {
+		p.pos++
+		p.symbol(c)
+		return
+	}
+	start := p.pos
+	for p.pos < len(d) && isAsciiLetter(d[p.pos]) {
+		p.pos++
+	}
+	word := string(d[start:p.pos])
+	numStart := p.pos
+	if p.pos < len(d) && d[p.pos] == '-' {
+		p.pos++
+	}
+	for p.pos < len(d) && d[p.pos] >= '0' && d[p.pos] <= '9' {
+		p.pos++
+	}
+	param, err := strconv.Atoi(string(d[numStart:p.pos]))
+	hasParam := err == nil
+	if !hasParam {
+		p.pos = numStart
+	}
+	if p.pos < len(d) && d[p.pos] == ' ' {
+		p.pos++
+	}
+	p.word(word, param, hasParam)
+}
+
+func isAsciiLetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// 控制符号，反斜杠后跟一个非字母字符
+func (p *rtfParser) symbol(c byte) {
+	switch c {
+	case '\'':
+		if p.pos+2 <= len(p.data) {
+			if v, err := strconv.ParseUint(string(p.data[p.pos:p.pos+2]), 16, 8); err == nil {
+				p.pos += 2
+				if p.state.skip {
+					return
+				}
+				if p.skipChars > 0 {
+					p.skipChars--
+					return
+				}
+				p.pending = append(p.pending, byte(v))
+			}
+		}
+	case '\\', '{', '}':
+		p.char(c)
+	case '~':
+		p.char(' ')
+	case '_':
+		p.char('-')
+	case '*':
+		p.state.skip = true
+	case '\r', '\n':
+		// 反斜杠加换行等同于 \par
+		if !p.state.skip {
+			p.flushBytes()
+			p.endParagraph()
+		}
+	}
+}
+
+func (p *rtfParser) word(word string, param int, hasParam bool) {
+	st := &p.state
+	if word == "bin" && hasParam {
+		// 二进制数据
+		p.pos = minInt(p.pos+maxInt(param, 0), len(p.data))
+		return
+	}
+	if st.skip {
+		return
+	}
+	p.flushBytes()
+	if word != "u" {
+		p.skipChars = 0
+	}
+	on := !hasParam || param != 0
+	switch word {
+	case "ansicpg":
+		p.ansicpg = param
+	case "deff":
+		p.deff = param
+	case "f":
+		if st.dest == "fonttbl" {
+			p.curFont = param
+		} else {
+			st.font = param
+		}
+	case "fcharset":
+		if st.dest == "fonttbl" {
+			p.fonts[p.curFont] = rtfCharsetCodePage(param)
+		}
+	case "cpg":
+		if st.dest == "fonttbl" {
+			p.fonts[p.curFont] = param
+		}
+	case "uc":
+		st.uc = maxInt(param, 0)
+	case "u":
+		p.unicode(param)
+	case "b":
+		st.bold = on
+	case "i":
+		st.italic = on
+	case "v":
+		st.hidden = on
+	case "plain":
+		st.bold, st.italic, st.hidden = false, false, false
+	case "pard":
+		// Word 在列表编号组中也会写 \pard，只处理正文中的
+		if st.dest == "" {
+			p.para = WordParagraph{}
+			p.inTable = false
+		}
+	case "s":
+		if st.dest == "stylesheet" {
+			p.styleNum = param
+		} else {
+			p.para.HeadingLevel = p.styles[param]
+		}
+	case "outlinelevel":
+		if param >= 0 && param < 9 {
+			if st.dest == "stylesheet" {
+				p.styleLvl = param + 1
+			} else {
+				p.para.HeadingLevel = param + 1
+			}
+		}
+	case "ls":
+		p.para.IsList = true
+	case "ilvl":
+		// 列表层级为 0-8
+		p.para.ListLevel = minInt(maxInt(param, 0), 8)
+	case "par", "nestcell":
+		p.endParagraph()
+	case "intbl":
+		p.inTable = true
+	case "cell":
+		p.inTable = true
+		p.endParagraph()
+		p.cells = append(p.cells, p.cell)
+		p.cell = nil
+	case "row":
+		p.endRow()
+	case "trowd":
+		p.defs, p.def = nil, rtfCellDef{}
+	case "clmrg":
+		p.def.hMerge = true
+	case "clvmgf":
+		p.def.vFirst = true
+	case "clvmrg":
+		p.def.vMerge = true
+	case "cellx":
+		p.defs = append(p.defs, p.def)
+		p.def = rtfCellDef{}
+	case "fonttbl", "stylesheet":
+		st.dest = word
+	case "listtext", "pntext":
+		st.dest = "listtext"
+		p.numText.Reset()
+	default:
+		if s, ok := rtfSymbols[word]; ok {
+			p.write(s)
+		} else if rtfSkipDests[word] {
+			st.skip = true
+		}
+	}
+}
+
+func (p *rtfParser) unicode(v int) {
+	if v < 0 {
+		v += 65536
+	}
+	p.skipChars = p.state.uc
+	r := rune(v)
+	switch {
+	case r >= 0xd800 && r < 0xdc00:
+		p.surrogate = r
+		return
+	case r >= 0xdc00 && r < 0xe000 && p.surrogate != 0:
+		r = utf16.DecodeRune(p.surrogate, r)
+	}
+	p.surrogate = 0
+	p.write(string(r))
+}
+
+// 文字字节，非 ASCII 字节和双字节编码的尾字节先缓存，与前面的字节一起解码
+func (p *rtfParser) char(c byte) {
+	if p.state.skip {
+		return
+	}
+	if p.skipChars > 0 {
+		p.skipChars--
+		return
+	}
+	if c >= 0x80 || (len(p.pending) > 0 && p.needTrail()) {
+		p.pending = append(p.pending, c)
+		return
+	}
+	p.flushBytes()
+	p.write(string(rune(c)))
+}
+
+// 缓存的字节是否以双字节字符的首字节结尾
+func (p *rtfParser) needTrail() bool {
+	if !isDbcsCodePage(p.codePage()) {
+		return false
+	}
+	i := 0
+	for i < len(p.pending) {
+		if p.pending[i] >= 0x81 {
+			i += 2
+		} else {
+			i++
+		}
+	}
+	return i > len(p.pending)
+}
+
+// 当前字体的代码页，字体没有指定字符集时使用文档的 \ansicpg
+func (p *rtfParser) codePage() int {
+	font := p.state.font
+	if font < 0 {
+		font = p.deff
+	}
+	if cp := p.fonts[font]; cp != 0 {
+		return cp
+	}
+	return p.ansicpg
+}
+
+func isDbcsCodePage(cp int) bool {
+	switch cp {
+	case 932, 936, 949, 950, 54936, 10002, 10008:
+		return true
+	}
+	return false
+}
+
+func (p *rtfParser) flushBytes() {
+	if len(p.pending) == 0 {
+		return
+	}
+	data := p.pending
+	p.pending = nil
+	cp := p.codePage()
+	if cp == 65001 && utf8.Valid(data) {
+		p.write(string(data))
+		return
+	}
+	// 中文文档常把汉字写在西文字体下，整段都是双字节字符时按 \ansicpg 解码
+	if cp == 1252 && isDbcsCodePage(p.ansicpg) && isDbcsRun(data) {
+		if text := rtfDecode(data, p.ansicpg); !strings.ContainsRune(text, utf8.RuneError) {
+			p.write(text)
+			return
+		}
+	}
+	p.write(rtfDecode(data, cp))
+}
+
+// 偶数个且都是双字节字符首字节范围内的字节
+func isDbcsRun(data []byte) bool {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return false
+	}
+	for _, b := range data {
+		if b < 0x81 {
+			return false
+		}
+	}
+	return true
+}
+
+func rtfDecode(data []byte, cp int) string {
+	enc := codePageEncoding(cp)
+	if enc == nil {
+		enc = codePageEncoding(1252)
+	}
+	out, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return ""
+	}
+	return string(out)
+}
+
+// 字体字符集对应的代码页，返回 0 时使用文档的 \ansicpg
+func rtfCharsetCodePage(charset int) int {
+	switch charset {
+	case 0, 2:
+		return 1252 // ANSI、Symbol
+	case 128:
+		return 932
+	case 129:
+		return 949
+	case 134:
+		return 936
+	case 136:
+		return 950
+	case 204:
+		return 1251
+	case 238:
+		return 1250
+	}
+	return 0
+}
+
+// 按当前目标输出文字
+func (p *rtfParser) write(s string) {
+	st := p.state
+	switch st.dest {
+	case "stylesheet":
+		p.styleText.WriteString(s)
+	case "listtext":
+		p.numText.WriteString(s)
+	case "":
+		if st.hidden {
+			return
+		}
+		if p.cur.Len() > 0 && (p.curBold != st.bold || p.curItalic != st.italic) {
+			p.flushSpan()
+		}
+		p.curBold, p.curItalic = st.bold, st.italic
+		p.cur.WriteString(s)
+	}
+}
+
+func (p *rtfParser) flushSpan() {
+	if p.cur.Len() > 0 {
+		p.spans = append(p.spans, WordSpan{Text: p.cur.String(), Bold: p.curBold, Italic: p.curItalic})
+		p.cur.Reset()
+	}
+}
+
+// 结束当前段落，表格中的段落放入当前单元格；段落属性保留到 \pard
+func (p *rtfParser) endParagraph() {
+	p.flushSpan()
+	spans := mergeSpans(p.spans)
+	p.spans = nil
+	para := p.para
+	if p.numbering != "" {
+		para.IsList = true
+		para.Ordered = utf8.RuneCountInString(p.numbering) > 1 || unicode.IsDigit([]rune(p.numbering)[0])
+		para.Numbering = p.numbering
+		if !para.Ordered {
+			para.Numbering = "•"
+		}
+		p.numbering = ""
+	}
+	if !p.inTable {
+		p.closeTable()
+	}
+	if len(spans) > 0 {
+		spans[0].Text = strings.TrimLeft(spans[0].Text, " ")
+		spans[len(spans)-1].Text = strings.TrimRight(spans[len(spans)-1].Text, " ")
+		spans = mergeSpans(spans)
+	}
+	for _, s := range spans {
+		para.Text += s.Text
+	}
+	if strings.TrimSpace(para.Text) == "" {
+		return
+	}
+	para.Spans = spans
+	if p.inTable {
+		p.cell = append(p.cell, WordBlock{Paragraph: &para})
+		return
+	}
+	p.blocks = append(p.blocks, WordBlock{Paragraph: &para})
+}
+
+// 按行定义的合并标记组成一行，横向合并并入左边的单元格，纵向合并标记为 Merged
+func (p *rtfParser) endRow() {
+	if p.table == nil {
+		p.table = &WordTable{}
+		p.vOrigin = map[int]cellPos{}
+	}
+	t := p.table
+	r := len(t.Rows)
+	row := WordRow{}
+	var defs []rtfCellDef
+	col := 0
+	for k, blocks := range p.cells {
+		var def rtfCellDef
+		if k < len(p.defs) {
+			def = p.defs[k]
+		}
+		col++
+		if def.hMerge && len(row.Cells) > 0 {
+			row.Cells[len(row.Cells)-1].ColSpan++
+			continue
+		}
+		row.Cells = append(row.Cells, WordCell{Text: blocksText(blocks), Blocks: blocks, Col: col - 1, ColSpan: 1, RowSpan: 1})
+		defs = append(defs, def)
+	}
+	for i := range row.Cells {
+		c := &row.Cells[i]
+		pos, ok := p.vOrigin[c.Col]
+		switch {
+		case defs[i].vMerge && ok:
+			t.Rows[pos.row].Cells[pos.cell].RowSpan++
+			*c = WordCell{Col: c.Col, ColSpan: c.ColSpan, Merged: true}
+		case defs[i].vFirst:
+			p.vOrigin[c.Col] = cellPos{row: r, cell: i}
+		default:
+			delete(p.vOrigin, c.Col)
+		}
+	}
+	p.cells = nil
+	t.Rows = append(t.Rows, row)
+	t.Cols = maxInt(t.Cols, col)
+}
+
+func (p *rtfParser) closeTable() {
+	if p.table != nil && len(p.table.Rows) > 0 {
+		p.blocks = append(p.blocks, WordBlock{Table: p.table})
+	}
+	p.table = nil
+}
@@ -0,0 +1,80 @@
+package office
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func parseRtfString(t *testing.T, src string) *WordDocument {
+	t.Helper()
+	name := filepath.Join(t.TempDir(), "test.rtf")
+	if err := os.WriteFile(name, []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	doc, err := parseRtf(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestRtfText(t *testing.T) {
+	// “中文”的 GBK 编码为 d6 d0 ce c4
+	doc := parseRtfString(t, `{\rtf1\ansi\ansicpg936\deff0{\fonttbl{\f0\fnil\fcharset134 SimSun;}{\f1\fswiss\fcharset0 Arial;}}
+{\stylesheet{\s0 Normal;}{\s1\outlinelevel0 heading 1;}}
+{\info{\title T}}
+\pard\s1 \'d6\'d0\'ce\'c4\par
+\pard\plain {\b bold} \u21834?\f1  caf\'e9 {\*\fldinst HYPERLINK "x"}{\fldrslt link}\par
+}`)
+	got := doc.Text()
+	want := "中文\nbold 啊 café link"
+	if got != want {
+		t.Fatalf("text = %q, want %q", got, want)
+	}
+	if p := doc.Paragraphs()[0]; p.HeadingLevel != 1 {
+		t.Errorf("heading level = %d", p.HeadingLevel)
+	}
+}
+
+func TestRtfListLevel(t *testing.T) {
+	doc := parseRtfString(t, `{\rtf1 {\listtext 1.}\pard\ls1\ilvl-1 hello\par{\listtext 2.}\pard\ls1\ilvl99 world\par}`)
+	paras := doc.Paragraphs()
+	if len(paras) != 2 || paras[0].ListLevel != 0 || paras[1].ListLevel != 8 {
+		t.Fatalf("paragraphs = %+v", paras)
+	}
+	if !strings.HasPrefix(doc.Text(), "1. hello") || !strings.HasPrefix(doc.Markdown(), "1. hello") {
+		t.Errorf("text = %q, markdown = %q", doc.Text(), doc.Markdown())
+	}
+}
+
+func TestRtfTable(t *testing.T) {
+	doc := parseRtfString(t, `{\rtf1
+\trowd\clvmgf\cellx1000\clmgf\cellx2000\clmrg\cellx3000
+\pard\intbl A\cell B\cell \cell\row
+\trowd\clvmrg\cellx1000\cellx2000\cellx3000
+\pard\intbl \cell C\cell D\cell\row
+\pard after\par}`)
+	tables := doc.Tables()
+	if len(tables) != 1 {
+		t.Fatalf("tables = %d", len(tables))
+	}
+	grid := tables[0].Grid()
+	want := [][]string{{"A", "B", "B"}, {"A", "C", "D"}}
+	for i := range want {
+		if strings.Join(grid[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("row %d = %q, want %q", i, grid[i], want[i])
+		}
+	}
+	if paras := doc.Paragraphs(); len(paras) != 1 || paras[0].Text != "after" {
+		t.Errorf("paragraphs = %+v", paras)
+	}
+}
+
+func TestRtfTruncated(t *testing.T) {
+	// 截断的文件不能越界或死循环
+	for _, src := range []string{`{\rtf1 \'`, `{\rtf1 \'d`, `{\rtf1 \bin99999 x`, `{\rtf1 \u`, `{\rtf1 {{{\b x`, `{\rtf1 }}}} x`} {
+		parseRtfString(t, src)
+	}
+}
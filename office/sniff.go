@@ -0,0 +1,178 @@
+package office
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+)
+
+// 按文件内容识别的格式
+const (
+	FormatDocx = "docx"
+	FormatDoc  = "doc"
+	FormatXlsx = "xlsx"
+	FormatXls  = "xls"
+	FormatCsv  = "csv"
+	FormatPptx = "pptx"
+	FormatPpt  = "ppt"
+	FormatTxt  = "txt"
+	FormatPdf  = "pdf"
+	FormatOdt  = "odt"
+	FormatOds  = "ods"
+	FormatOdp  = "odp"
+	FormatHtml = "html"
+	FormatMd   = "md"
+	FormatRtf  = "rtf"
+	FormatEpub = "epub"
+)
+
+var errUnsupportedFormat = errors.New("不支持的文件格式！")
+
+var zipMagic = []byte("PK\x03\x04")
+
+// OOXML 主部件的内容类型，含启用宏和模板的变体
+var ooxmlContentTypes = []struct {
+	keyword string
+	format  string
+}{
+	{"wordprocessingml", FormatDocx},
+	{"ms-word", FormatDocx},
+	{"spreadsheetml", FormatXlsx},
+	{"ms-excel", FormatXlsx},
+	{"presentationml", FormatPptx},
+	{"ms-powerpoint", FormatPptx},
+}
+
+// 按文件头识别格式，不看后缀；纯文本文件再按开头的标签和后缀区分 csv/tsv、html、markdown 与 txt
+func detectFormat(filePath string, suffix string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", errors.New("打开文件失败！")
+	}
+	head := make([]byte, 1024)
+	n, _ := io.ReadFull(f, head)
+	f.Close()
+	head = head[:n]
+
+	switch {
+	case bytes.HasPrefix(head, oleMagic):
+		return oleFormat(filePath)
+	case bytes.HasPrefix(head, zipMagic):
+		return zipFormat(filePath)
+	case bytes.Contains(head, []byte("%PDF-")):
+		return FormatPdf, nil
+	case bytes.HasPrefix(bytes.TrimPrefix(head, bomUTF8), []byte(`{\rtf`)):
+		return FormatRtf, nil
+	}
+	if !looksLikeText(head) {
+		return "", errUnsupportedFormat
+	}
+	switch {
+	case isCsvSuffix(suffix):
+		return FormatCsv, nil
+	case looksLikeHtml(head), isHtmlSuffix(suffix):
+		return FormatHtml, nil
+	case isMarkdownSuffix(suffix):
+		return FormatMd, nil
+	}
+	return FormatTxt, nil
+}
+
+// 复合文档按其中的流区分 doc/xls/ppt，加密的 OOXML 文件也是复合文档
+func oleFormat(filePath string) (string, error) {
+	names, err := oleStreamNames(filePath)
+	if err != nil {
+		return "", errors.New("打开文件失败！")
+	}
+	switch {
+	case matchFold(names, "EncryptedPackage") != "":
+		return "", errOleEncrypted
+	case matchFold(names, "WordDocument") != "":
+		return FormatDoc, nil
+	case matchFold(names, "Workbook") != "", matchFold(names, "Book") != "":
+		return FormatXls, nil
+	case matchFold(names, "PowerPoint Document") != "":
+		return FormatPpt, nil
+	}
+	return "", errUnsupportedFormat
+}
+
+// OpenDocument 包第一个文件 mimetype 的内容
+var odfMimeTypes = map[string]string{
+	"application/vnd.oasis.opendocument.text":                  FormatOdt,
+	"application/vnd.oasis.opendocument.text-template":         FormatOdt,
+	"application/vnd.oasis.opendocument.spreadsheet":           FormatOds,
+	"application/vnd.oasis.opendocument.spreadsheet-template":  FormatOds,
+	"application/vnd.oasis.opendocument.presentation":          FormatOdp,
+	"application/vnd.oasis.opendocument.presentation-template": FormatOdp,
+}
+
+// zip 包按 [Content_Types].xml 中主部件的内容类型区分 docx/xlsx/pptx，
+// 缺少内容类型时按主文档部件所在的目录判断；OpenDocument 和 epub 按 mimetype 文件区分
+func zipFormat(filePath string) (string, error) {
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return "", errors.New("打开文件失败！")
+	}
+	defer pkg.Close()
+
+	if mimeType, err := pkg.read("mimetype"); err == nil {
+		mimeType := strings.TrimSpace(string(mimeType))
+		if mimeType == "application/epub+zip" {
+			return FormatEpub, nil
+		}
+		if format, ok := odfMimeTypes[mimeType]; ok {
+			return format, nil
+		}
+	}
+
+	var types struct {
+		Overrides []struct {
+			ContentType string `xml:"ContentType,attr"`
+		} `xml:"Override"`
+	}
+	if pkg.decode("[Content_Types].xml", &types) == nil {
+		for _, o := range types.Overrides {
+			if !strings.HasSuffix(o.ContentType, ".main+xml") {
+				continue
+			}
+			for _, t := range ooxmlContentTypes {
+				if strings.Contains(o.ContentType, t.keyword) {
+					return t.format, nil
+				}
+			}
+		}
+	}
+	switch main := pkg.relTarget("", relOfficeDocument); {
+	case strings.HasPrefix(main, "word/"):
+		return FormatDocx, nil
+	case strings.HasPrefix(main, "xl/"):
+		return FormatXlsx, nil
+	case strings.HasPrefix(main, "ppt/"):
+		return FormatPptx, nil
+	}
+	// 缺少 mimetype 的 epub
+	if pkg.has("META-INF/container.xml") {
+		return FormatEpub, nil
+	}
+	return "", errUnsupportedFormat
+}
+
+// 文件开头没有零字节和成片的控制字符时视为文本，UTF-16 文本按编码检测结果判断
+func looksLikeText(head []byte) bool {
+	if enc := detectEncoding(head); enc == EncodingUTF16LE || enc == EncodingUTF16BE {
+		return true
+	}
+	control := 0
+	for _, b := range head {
+		switch {
+		case b == 0:
+			return false
+		case b < 0x20 && b != '\t' && b != '\n' && b != '\r' && b != '\f' && b != 0x1b:
+			control++
+		}
+	}
+	return control*20 <= len(head)
+}
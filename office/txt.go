@@ -0,0 +1,81 @@
+package office
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// txt读取选项
+type TxtOptions struct {
+	Encoding      string // 文本编码，见 Encoding* 常量，为空时自动检测
+	PreserveLines bool   // 保留换行，默认各行以空格连接为一行
+}
+
+// txt文件按选项转文字
+func TxtToContentWithOptions(filePath string, opts TxtOptions) (word string, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return "", "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return "", "", 0, errors.New("计算文件大小失败！")
+	}
+
+	text, err := parseTxt(filePath, opts)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return text, suffix, size, nil
+}
+
+// txt地址文件按选项转文字
+func TxtUrlToContentWithOptions(url string, opts TxtOptions) (word string, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return "", "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return "", "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return "", "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return "", "", 0, errors.New("计算文件大小失败！")
+	}
+
+	text, err := parseTxt(filePath, opts)
+	if err != nil {
+		return "", "", 0, err
+	}
+	return text, suffix, size, nil
+}
+
+func parseTxt(filePath string, opts TxtOptions) (string, error) {
+	content, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return "", errors.New("读取文件失败！")
+	}
+	text, _, err := decodeText(content, opts.Encoding)
+	if err != nil {
+		return "", err
+	}
+	// 统一 Windows 和旧版 Mac 的换行
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+	if opts.PreserveLines {
+		return text, nil
+	}
+	return strings.Replace(text, "\n", " ", -1), nil
+}
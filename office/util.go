@@ -1,76 +1,76 @@
 package office
 
 import (
-	"crypto/md5"
 	"errors"
-	"fmt"
-	"io"
-	"io/ioutil"
-	"net/http"
 	"os"
 	"strings"
+
+	"github.com/bangongyi/toolkits/workspace"
 )
 
-func saveFile(url string, suffix string) (string, error) {
-	byString, err := md5ByString(url)
-	if err != nil {
-		return "", err
+func getSuffix(url string) (string, error) {
+	dotIndex := strings.LastIndex(url, ".")
+	if dotIndex == -1 || dotIndex == len(url)-1 {
+		return "", errors.New("没有文件后缀或者文件名以点号结尾！")
 	}
-	targetName := "temporary" + byString + "." + suffix
+	suffix := url[dotIndex+1:]
+	return suffix, nil
+}
 
-	// 发起GET请求
-	response, err := http.Get(url)
+func countSize(filePath string) (int, error) {
+	fileInfo, err := os.Stat(filePath)
 	if err != nil {
-		return "", err
+		return 0, errors.New("计算文件大小失败！")
 	}
-	defer response.Body.Close()
 
-	// 创建临时文件
-	tmpFile, err := ioutil.TempFile("/tmp", "tempfile")
+	fileSize := int(fileInfo.Size())
+	return fileSize, nil
+}
+
+// 本地文件按 parse 转为 word 结构化内容，供 html/markdown/rtf/epub 等格式共用
+func fileToWord(filePath string, parse func(filePath string) (*WordDocument, error)) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
 	if err != nil {
-		return "", err
+		return nil, "", 0, errors.New("获取前缀失败！")
 	}
-	defer tmpFile.Close()
-
-	// 将HTTP响应的内容写入临时文件
-	_, err = io.Copy(tmpFile, response.Body)
+	size, err := countSize(filePath)
 	if err != nil {
-		return "", errors.New("写入临时文件时出错！")
+		return nil, "", 0, errors.New("计算文件大小失败！")
 	}
 
-	// 重命名临时文件为目标文件名并保存到本地/tmp目录下
-	err = os.Rename(tmpFile.Name(), "/tmp/"+targetName)
+	doc, err = parse(filePath)
 	if err != nil {
-		return "", errors.New("重命名临时文件失败！")
+		return nil, "", 0, err
 	}
-	return "/tmp/" + targetName, nil
+	return doc, suffix, size, nil
 }
 
-func getSuffix(url string) (string, error) {
-	dotIndex := strings.LastIndex(url, ".")
-	if dotIndex == -1 || dotIndex == len(url)-1 {
-		return "", errors.New("没有文件后缀或者文件名以点号结尾！")
+// 地址文件下载到临时目录后按 parse 转为 word 结构化内容
+func urlToWord(url string, parse func(filePath string) (*WordDocument, error)) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
 	}
-	suffix := url[dotIndex+1:]
-	return suffix, nil
-}
 
-func countSize(filePath string) (int, error) {
-	fileInfo, err := os.Stat(filePath)
+	ws, err := workspace.New("")
 	if err != nil {
-		return 0, errors.New("计算文件大小失败！")
+		return nil, "", 0, errors.New("创建临时目录失败！")
 	}
+	defer ws.Cleanup()
 
-	fileSize := int(fileInfo.Size())
-	return fileSize, nil
-}
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
 
-func md5ByString(str string) (string, error) {
-	m := md5.New()
-	_, err := io.WriteString(m, str)
+	doc, err = parse(filePath)
 	if err != nil {
-		return "", err
+		return nil, "", 0, err
 	}
-	arr := m.Sum(nil)
-	return fmt.Sprintf("%x", arr), nil
+	return doc, suffix, size, nil
 }
@@ -0,0 +1,682 @@
+package office
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"baliance.com/gooxml/document"
+	"baliance.com/gooxml/schema/soo/ofc/sharedTypes"
+	"baliance.com/gooxml/schema/soo/wml"
+
+	"github.com/bangongyi/toolkits/workspace"
+)
+
+// word结构化内容
+type WordDocument struct {
+	Blocks []WordBlock `json:"blocks"`          // 按文档顺序排列的段落和表格
+	Parts  []WordPart  `json:"parts,omitempty"` // 正文以外的内容，由 WordOptions 控制
+	// 修订列表，仅 WordRevisionAnnotate 模式下返回
+	Revisions []WordRevision `json:"revisions,omitempty"`
+	Chapters  []WordChapter  `json:"chapters,omitempty"` // 章节，仅 epub 返回
+}
+
+// 章节，对应 Blocks[Start:End]
+type WordChapter struct {
+	Title string `json:"title"`
+	Href  string `json:"href"` // 章节文件在包内的路径
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+}
+
+// 块级元素，段落和表格二选一
+type WordBlock struct {
+	Paragraph *WordParagraph `json:"paragraph,omitempty"`
+	Table     *WordTable     `json:"table,omitempty"`
+}
+
+// word段落
+type WordParagraph struct {
+	Text         string     `json:"text"`
+	Style        string     `json:"style,omitempty"`         // 段落样式ID
+	HeadingLevel int        `json:"heading_level,omitempty"` // 标题级别 1-9，正文为 0
+	IsList       bool       `json:"is_list,omitempty"`       // 是否为列表项
+	ListLevel    int        `json:"list_level,omitempty"`    // 列表层级，从 0 开始
+	NumId        int64      `json:"num_id,omitempty"`        // 列表编号定义ID
+	Ordered      bool       `json:"ordered,omitempty"`       // 有序列表（非项目符号）
+	Numbering    string     `json:"numbering,omitempty"`     // 渲染后的编号，如 "1." "(a)" "•"
+	Spans        []WordSpan `json:"spans,omitempty"`
+}
+
+// 段落中格式相同的一段文字
+type WordSpan struct {
+	Text     string `json:"text"`
+	Bold     bool   `json:"bold,omitempty"`
+	Italic   bool   `json:"italic,omitempty"`
+	Revision string `json:"revision,omitempty"` // 标注模式下的修订类型，见 WordRevision* 常量
+}
+
+// word文件转结构化内容，只提取正文
+func WordToStructure(filePath string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return WordToStructureWithOptions(filePath, WordOptions{})
+}
+
+// word地址文件转结构化内容，只提取正文
+func WordUrlToStructure(url string) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	return WordUrlToStructureWithOptions(url, WordOptions{})
+}
+
+// word文件按选项转结构化内容
+func WordToStructureWithOptions(filePath string, opts WordOptions) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	doc, err = parseWord(filePath, opts)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return doc, suffix, size, nil
+}
+
+// word地址文件按选项转结构化内容
+func WordUrlToStructureWithOptions(url string, opts WordOptions) (doc *WordDocument, fileSuffix string, FileSize int, err error) {
+	suffix, err := getSuffix(url)
+	if err != nil {
+		return nil, "", 0, errors.New("获取前缀失败！")
+	}
+
+	ws, err := workspace.New("")
+	if err != nil {
+		return nil, "", 0, errors.New("创建临时目录失败！")
+	}
+	defer ws.Cleanup()
+
+	filePath, err := ws.Download(url, suffix)
+	if err != nil {
+		return nil, "", 0, errors.New("文件保存在本地失败！")
+	}
+
+	size, err := countSize(filePath)
+	if err != nil {
+		return nil, "", 0, errors.New("计算文件大小失败！")
+	}
+
+	doc, err = parseWord(filePath, opts)
+	if err != nil {
+		return nil, "", 0, err
+	}
+	return doc, suffix, size, nil
+}
+
+// 所有正文段落，不含表格中的段落
+func (d *WordDocument) Paragraphs() []WordParagraph {
+	var list []WordParagraph
+	for _, b := range d.Blocks {
+		if b.Paragraph != nil {
+			list = append(list, *b.Paragraph)
+		}
+	}
+	return list
+}
+
+// 所有正文表格，嵌套表格在单元格的 Blocks 中
+func (d *WordDocument) Tables() []*WordTable {
+	var list []*WordTable
+	for _, b := range d.Blocks {
+		if b.Table != nil {
+			list = append(list, b.Table)
+		}
+	}
+	return list
+}
+
+// 纯文本，段落之间换行，列表项带编号，表格每行一行、单元格以制表符分隔
+// 正文以外的内容附在最后，每项一行并带来源标签
+func (d *WordDocument) Text() string {
+	lines := append(partsText(d.Parts), revisionsText(d.Revisions)...)
+	if len(d.Blocks) > 0 {
+		lines = append([]string{blocksText(d.Blocks)}, lines...)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Markdown，标题转为 #，列表转为 - / 1.，表格转为管道表格，保留粗体和斜体
+// 正文以外的内容以分隔线隔开附在最后
+func (d *WordDocument) Markdown() string {
+	sections := []string{}
+	if len(d.Blocks) > 0 {
+		sections = append(sections, blocksMarkdown(d.Blocks))
+	}
+	if len(d.Parts) > 0 {
+		sections = append(sections, strings.Join(partsMarkdown(d.Parts), "\n\n"))
+	}
+	if len(d.Revisions) > 0 {
+		sections = append(sections, strings.Join(revisionsMarkdown(d.Revisions), "\n"))
+	}
+	return strings.Join(sections, "\n\n---\n\n")
+}
+
+func blocksText(blocks []WordBlock) string {
+	lines := make([]string, 0, len(blocks))
+	for _, b := range blocks {
+		if b.Table != nil {
+			lines = append(lines, b.Table.Text())
+			continue
+		}
+		p := b.Paragraph
+		line := p.Text
+		if p.Numbering != "" {
+			line = strings.Repeat("  ", maxInt(p.ListLevel, 0)) + p.Numbering + " " + line
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func blocksMarkdown(blocks []WordBlock) string {
+	var sb strings.Builder
+	for i, b := range blocks {
+		if i > 0 {
+			// 连续的列表项之间不空行
+			if b.Paragraph != nil && b.Paragraph.IsList && blocks[i-1].Paragraph != nil && blocks[i-1].Paragraph.IsList {
+				sb.WriteString("\n")
+			} else {
+				sb.WriteString("\n\n")
+			}
+		}
+		if b.Table != nil {
+			sb.WriteString(b.Table.Markdown())
+		} else {
+			sb.WriteString(b.Paragraph.Markdown())
+		}
+	}
+	return sb.String()
+}
+
+// 单个段落的 Markdown
+func (p WordParagraph) Markdown() string {
+	var body string
+	if p.HeadingLevel > 0 {
+		// 标题自带加粗，不再重复标记
+		body = markdownEscape(p.Text)
+	} else {
+		body = spansMarkdown(p.Spans)
+	}
+
+	switch {
+	case p.HeadingLevel > 0:
+		return strings.Repeat("#", minInt(p.HeadingLevel, 6)) + " " + body
+	case p.IsList:
+		marker := "-"
+		if p.Ordered {
+			marker = "1."
+		}
+		return strings.Repeat("  ", maxInt(p.ListLevel, 0)) + marker + " " + body
+	}
+	return body
+}
+
+func spansMarkdown(spans []WordSpan) string {
+	var sb strings.Builder
+	for _, s := range spans {
+		text := markdownEscape(s.Text)
+		// 标记符不能包住首尾空白，否则不生效
+		trimmed := strings.TrimSpace(text)
+		if trimmed == "" || (!s.Bold && !s.Italic && s.Revision == "") {
+			sb.WriteString(text)
+			continue
+		}
+		open := ""
+		if s.Bold {
+			open += "**"
+		}
+		if s.Italic {
+			open += "*"
+		}
+		close := reverseString(open)
+		switch s.Revision {
+		case WordRevisionInsert:
+			open, close = "<ins>"+open, close+"</ins>"
+		case WordRevisionDelete:
+			open, close = "~~"+open, close+"~~"
+		}
+		lead := text[:strings.Index(text, trimmed)]
+		tail := text[len(lead)+len(trimmed):]
+		sb.WriteString(lead + open + trimmed + close + tail)
+	}
+	return sb.String()
+}
+
+var markdownSpecial = regexp.MustCompile("([\\\\`*_\\[\\]#|])")
+
+func markdownEscape(s string) string {
+	return markdownSpecial.ReplaceAllString(s, "\\$1")
+}
+
+func reverseString(s string) string {
+	r := []rune(s)
+	for i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {
+		r[i], r[j] = r[j], r[i]
+	}
+	return string(r)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func parseWord(filePath string, opts WordOptions) (*WordDocument, error) {
+	// doc、odt 格式只读取正文，WordOptions 不生效
+	if isOleFile(filePath) {
+		return parseLegacyWord(filePath)
+	}
+	if odfFileFormat(filePath) == FormatOdt {
+		return parseOdt(filePath)
+	}
+	doc, err := document.Open(filePath)
+	if err != nil {
+		return nil, errors.New("打开文件失败！")
+	}
+	e := newWordExtractor(doc, opts)
+	res := &WordDocument{}
+	if body := doc.X().Body; body != nil {
+		res.Blocks = e.blocks(body.EG_BlockLevelElts)
+	}
+	res.Parts, err = e.parts(filePath, doc, opts)
+	if err != nil {
+		return nil, errors.New("读取文件内容失败！")
+	}
+	res.Revisions = e.revisions
+	return res, nil
+}
+
+// 编号级别定义
+type numberingLevel struct {
+	format string // numFmt，如 decimal、bullet
+	text   string // lvlText，如 "%1.%2."
+	start  int
+}
+
+type wordExtractor struct {
+	// 样式ID -> 标题级别
+	headingStyles map[string]int
+	// numId -> 各级别定义
+	numbering map[int64][]numberingLevel
+	// numId -> 各级别当前计数
+	counters map[int64][]int
+
+	// 修订处理方式
+	revisionMode WordRevisionMode
+	// 标注模式下收集的修订
+	revisions []WordRevision
+}
+
+func newWordExtractor(doc *document.Document, opts WordOptions) *wordExtractor {
+	e := &wordExtractor{
+		headingStyles: map[string]int{},
+		numbering:     map[int64][]numberingLevel{},
+		counters:      map[int64][]int{},
+		revisionMode:  opts.Revisions,
+	}
+	e.loadStyles(doc)
+	e.loadNumbering(doc)
+	return e
+}
+
+var headingName = regexp.MustCompile(`^(?i)(heading|标题)\s*([1-9])$`)
+
+func (e *wordExtractor) loadStyles(doc *document.Document) {
+	styles := doc.Styles.X()
+	if styles == nil {
+		return
+	}
+	for _, s := range styles.Style {
+		if s.StyleIdAttr == nil {
+			continue
+		}
+		level := 0
+		if s.Name != nil {
+			name := strings.TrimSpace(s.Name.ValAttr)
+			if m := headingName.FindStringSubmatch(name); m != nil {
+				level, _ = strconv.Atoi(m[2])
+			} else if strings.EqualFold(name, "title") || name == "标题" {
+				level = 1
+			}
+		}
+		if level == 0 && s.PPr != nil && s.PPr.OutlineLvl != nil && s.PPr.OutlineLvl.ValAttr < 9 {
+			level = int(s.PPr.OutlineLvl.ValAttr) + 1
+		}
+		if level > 0 {
+			e.headingStyles[*s.StyleIdAttr] = level
+		}
+	}
+}
+
+func (e *wordExtractor) loadNumbering(doc *document.Document) {
+	numbering := doc.Numbering.X()
+	if numbering == nil {
+		return
+	}
+	abstract := map[int64][]numberingLevel{}
+	for _, an := range numbering.AbstractNum {
+		levels := make([]numberingLevel, 9)
+		for _, lvl := range an.Lvl {
+			if lvl.IlvlAttr < 0 || lvl.IlvlAttr >= 9 {
+				continue
+			}
+			l := numberingLevel{format: "decimal", start: 1}
+			if lvl.NumFmt != nil {
+				l.format = lvl.NumFmt.ValAttr.String()
+			}
+			if lvl.LvlText != nil && lvl.LvlText.ValAttr != nil {
+				l.text = *lvl.LvlText.ValAttr
+			}
+			if lvl.Start != nil {
+				l.start = clampListNumber(int(lvl.Start.ValAttr))
+			}
+			levels[lvl.IlvlAttr] = l
+		}
+		abstract[an.AbstractNumIdAttr] = levels
+	}
+	for _, num := range numbering.Num {
+		if num.AbstractNumId != nil {
+			e.numbering[num.NumIdAttr] = abstract[num.AbstractNumId.ValAttr]
+		}
+	}
+}
+
+// 遍历块级元素，按文档顺序返回段落和表格
+func (e *wordExtractor) blocks(elts []*wml.EG_BlockLevelElts) []WordBlock {
+	var blocks []WordBlock
+	for _, elt := range elts {
+		blocks = append(blocks, e.contentBlocks(elt.EG_ContentBlockContent)...)
+	}
+	return blocks
+}
+
+func (e *wordExtractor) contentBlocks(content []*wml.EG_ContentBlockContent) []WordBlock {
+	var blocks []WordBlock
+	// 解析时每个 p/tbl 各占一个 EG_ContentBlockContent，顺序即文档顺序
+	for _, c := range content {
+		for _, p := range c.P {
+			if para := e.paragraph(p); para != nil {
+				blocks = append(blocks, WordBlock{Paragraph: para})
+			}
+		}
+		for _, tbl := range c.Tbl {
+			if table := e.table(tbl); table != nil {
+				blocks = append(blocks, WordBlock{Table: table})
+			}
+		}
+		// 内容控件中的段落
+		if c.Sdt != nil && c.Sdt.SdtContent != nil {
+			blocks = append(blocks, e.contentBlocks(c.Sdt.SdtContent.EG_ContentBlockContent)...)
+		}
+		if c.CustomXml != nil {
+			blocks = append(blocks, e.contentBlocks(c.CustomXml.EG_ContentBlockContent)...)
+		}
+	}
+	return blocks
+}
+
+func (e *wordExtractor) paragraph(p *wml.CT_P) *WordParagraph {
+	para := WordParagraph{}
+	para.Spans = mergeSpans(e.runContent(p.EG_PContent))
+	for _, s := range para.Spans {
+		para.Text += s.text()
+	}
+	if strings.TrimSpace(para.Text) == "" {
+		return nil
+	}
+
+	var numPr *wml.CT_NumPr
+	if p.PPr != nil {
+		if p.PPr.PStyle != nil {
+			para.Style = p.PPr.PStyle.ValAttr
+			para.HeadingLevel = e.headingStyles[para.Style]
+		}
+		if p.PPr.OutlineLvl != nil && p.PPr.OutlineLvl.ValAttr < 9 {
+			para.HeadingLevel = int(p.PPr.OutlineLvl.ValAttr) + 1
+		}
+		numPr = p.PPr.NumPr
+	}
+	// numId 为 0 表示取消编号
+	if numPr != nil && numPr.NumId != nil && numPr.NumId.ValAttr != 0 {
+		para.IsList = true
+		para.NumId = numPr.NumId.ValAttr
+		if numPr.Ilvl != nil {
+			para.ListLevel = int(numPr.Ilvl.ValAttr)
+		}
+		para.Numbering, para.Ordered = e.nextNumber(para.NumId, para.ListLevel)
+	}
+	return &para
+}
+
+// 收集段落内容中的文字，包括超链接、域和内容控件
+func (e *wordExtractor) runContent(content []*wml.EG_PContent) []WordSpan {
+	var spans []WordSpan
+	for _, pc := range content {
+		for _, crc := range pc.EG_ContentRunContent {
+			spans = append(spans, e.contentRun(crc)...)
+		}
+		if pc.Hyperlink != nil {
+			spans = append(spans, e.runContent(pc.Hyperlink.EG_PContent)...)
+		}
+		for _, f := range pc.FldSimple {
+			spans = append(spans, e.runContent(f.EG_PContent)...)
+		}
+	}
+	return spans
+}
+
+func (e *wordExtractor) contentRun(crc *wml.EG_ContentRunContent) []WordSpan {
+	var spans []WordSpan
+	if crc.R != nil {
+		spans = append(spans, runSpan(crc.R))
+	}
+	if crc.Sdt != nil && crc.Sdt.SdtContent != nil {
+		spans = append(spans, e.runContent(crc.Sdt.SdtContent.EG_PContent)...)
+	}
+	if crc.SmartTag != nil {
+		spans = append(spans, e.runContent(crc.SmartTag.EG_PContent)...)
+	}
+	if crc.CustomXml != nil {
+		spans = append(spans, e.runContent(crc.CustomXml.EG_PContent)...)
+	}
+	for _, rle := range crc.EG_RunLevelElements {
+		spans = append(spans, e.runLevel(rle)...)
+	}
+	return spans
+}
+
+func runSpan(r *wml.CT_R) WordSpan {
+	span := WordSpan{Text: runText(r)}
+	if r.RPr != nil {
+		span.Bold = onOff(r.RPr.B)
+		span.Italic = onOff(r.RPr.I)
+	}
+	return span
+}
+
+func runText(r *wml.CT_R) string {
+	var sb strings.Builder
+	for _, ic := range r.EG_RunInnerContent {
+		switch {
+		case ic.T != nil:
+			sb.WriteString(ic.T.Content)
+		case ic.DelText != nil:
+			// 只出现在删除修订中，是否保留由修订模式决定
+			sb.WriteString(ic.DelText.Content)
+		case ic.Tab != nil:
+			sb.WriteString("\t")
+		case ic.Br != nil, ic.Cr != nil:
+			sb.WriteString("\n")
+		case ic.NoBreakHyphen != nil:
+			sb.WriteString("-")
+		}
+	}
+	return sb.String()
+}
+
+func onOff(v *wml.CT_OnOff) bool {
+	if v == nil {
+		return false
+	}
+	if v.ValAttr == nil {
+		return true
+	}
+	if v.ValAttr.Bool != nil {
+		return *v.ValAttr.Bool
+	}
+	return v.ValAttr.ST_OnOff1 != sharedTypes.ST_OnOff1Off
+}
+
+// 合并相邻的同格式片段
+func mergeSpans(spans []WordSpan) []WordSpan {
+	var merged []WordSpan
+	for _, s := range spans {
+		if s.Text == "" {
+			continue
+		}
+		if n := len(merged); n > 0 && merged[n-1].Bold == s.Bold && merged[n-1].Italic == s.Italic && merged[n-1].Revision == s.Revision {
+			merged[n-1].Text += s.Text
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// 计算列表项编号，返回编号文本和是否有序
+func (e *wordExtractor) nextNumber(numId int64, level int) (string, bool) {
+	if level < 0 || level >= 9 {
+		level = 0
+	}
+	levels := e.numbering[numId]
+	counters, ok := e.counters[numId]
+	if !ok {
+		counters = make([]int, 9)
+		e.counters[numId] = counters
+	}
+	def := numberingLevel{format: "decimal", text: "%" + strconv.Itoa(level+1) + ".", start: 1}
+	if level < len(levels) && levels[level].format != "" {
+		def = levels[level]
+	}
+
+	if counters[level] == 0 {
+		counters[level] = clampListNumber(def.start)
+	} else {
+		counters[level] = clampListNumber(counters[level] + 1)
+	}
+	// 上级编号递增后，下级重新计数
+	for i := level + 1; i < len(counters); i++ {
+		counters[i] = 0
+	}
+
+	if def.format == "bullet" {
+		return "•", false
+	}
+	if def.format == "none" {
+		return "", true
+	}
+	label := def.text
+	if label == "" {
+		label = "%" + strconv.Itoa(level+1) + "."
+	}
+	for i := 0; i <= level; i++ {
+		format := "decimal"
+		start := 1
+		if i < len(levels) && levels[i].format != "" {
+			format = levels[i].format
+			start = levels[i].start
+		}
+		n := counters[i]
+		if n == 0 {
+			n = start
+		}
+		label = strings.ReplaceAll(label, "%"+strconv.Itoa(i+1), formatNumber(n, format))
+	}
+	return label, true
+}
+
+var chineseDigits = []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+
+// 列表编号的取值范围，文件中的起始值和计数超出时截断
+const maxListNumber = 32767
+
+func clampListNumber(n int) int {
+	return minInt(maxInt(n, 0), maxListNumber)
+}
+
+// 字母编号最多重复 30 次（zzz...），罗马数字最大 3999，超出范围时用阿拉伯数字
+func formatNumber(n int, format string) string {
+	n = clampListNumber(n)
+	switch format {
+	case "lowerLetter", "upperLetter":
+		if n < 1 || n > 26*30 {
+			break
+		}
+		s := strings.Repeat(string(rune('a'+(n-1)%26)), (n-1)/26+1)
+		if format == "upperLetter" {
+			s = strings.ToUpper(s)
+		}
+		return s
+	case "lowerRoman", "upperRoman":
+		if n < 1 || n > 3999 {
+			break
+		}
+		if format == "lowerRoman" {
+			return strings.ToLower(roman(n))
+		}
+		return roman(n)
+	case "chineseCounting", "chineseCountingThousand", "ideographTraditional", "taiwaneseCountingThousand":
+		return chineseNumber(n)
+	case "decimalEnclosedCircle", "decimalEnclosedCircleChinese":
+		if n >= 1 && n <= 20 {
+			return string(rune('①' + n - 1))
+		}
+	}
+	return strconv.Itoa(n)
+}
+
+func roman(n int) string {
+	values := []int{1000, 900, 500, 400, 100, 90, 50, 40, 10, 9, 5, 4, 1}
+	symbols := []string{"M", "CM", "D", "CD", "C", "XC", "L", "XL", "X", "IX", "V", "IV", "I"}
+	var sb strings.Builder
+	for i, v := range values {
+		for n >= v {
+			sb.WriteString(symbols[i])
+			n -= v
+		}
+	}
+	return sb.String()
+}
+
+// 简单的中文计数，支持 1-99
+func chineseNumber(n int) string {
+	if n <= 0 || n >= 100 {
+		return strconv.Itoa(n)
+	}
+	if n < 10 {
+		return chineseDigits[n]
+	}
+	s := ""
+	if n/10 > 1 {
+		s = chineseDigits[n/10]
+	}
+	s += "十"
+	if n%10 > 0 {
+		s += chineseDigits[n%10]
+	}
+	return s
+}
@@ -0,0 +1,275 @@
+package office
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"baliance.com/gooxml/document"
+	"baliance.com/gooxml/schema/soo/wml"
+)
+
+// word提取选项，默认只提取正文
+type WordOptions struct {
+	HeadersFooters bool // 页眉页脚
+	Notes          bool // 脚注和尾注
+	Comments       bool // 批注，含作者和批注的正文范围
+	TextBoxes      bool // 文本框和形状中的文字
+	// 修订处理方式，默认接受所有修订
+	Revisions WordRevisionMode
+}
+
+// 正文以外内容的来源
+const (
+	WordSourceHeader   = "header"
+	WordSourceFooter   = "footer"
+	WordSourceFootnote = "footnote"
+	WordSourceEndnote  = "endnote"
+	WordSourceComment  = "comment"
+	WordSourceTextBox  = "textbox"
+)
+
+var wordSourceLabels = map[string]string{
+	WordSourceHeader:   "页眉",
+	WordSourceFooter:   "页脚",
+	WordSourceFootnote: "脚注",
+	WordSourceEndnote:  "尾注",
+	WordSourceComment:  "批注",
+	WordSourceTextBox:  "文本框",
+}
+
+const (
+	nsWord = "http://schemas.openxmlformats.org/wordprocessingml/2006/main"
+	nsMc   = "http://schemas.openxmlformats.org/markup-compatibility/2006"
+)
+
+// 正文以外的内容，如页眉、脚注、批注
+type WordPart struct {
+	Source string      `json:"source"`           // 来源，见 WordSource* 常量
+	Id     string      `json:"id,omitempty"`     // 脚注、尾注、批注编号
+	Author string      `json:"author,omitempty"` // 批注作者
+	Date   *time.Time  `json:"date,omitempty"`   // 批注时间
+	Anchor string      `json:"anchor,omitempty"` // 批注所指向的正文文字
+	Text   string      `json:"text"`
+	Blocks []WordBlock `json:"blocks,omitempty"`
+}
+
+// 来源标签，如 "[脚注1]" "[批注2 张三]"
+func (p WordPart) Label() string {
+	label := wordSourceLabels[p.Source]
+	if label == "" {
+		label = p.Source
+	}
+	label += p.Id
+	if p.Author != "" {
+		label += " " + p.Author
+	}
+	return "[" + label + "]"
+}
+
+func partsText(parts []WordPart) []string {
+	lines := make([]string, 0, len(parts))
+	for _, p := range parts {
+		line := p.Label()
+		if p.Anchor != "" {
+			line += " 「" + p.Anchor + "」"
+		}
+		lines = append(lines, line+" "+p.Text)
+	}
+	return lines
+}
+
+func partsMarkdown(parts []WordPart) []string {
+	list := make([]string, 0, len(parts))
+	for _, p := range parts {
+		line := "**" + markdownEscape(p.Label()) + "**"
+		if p.Anchor != "" {
+			line += " > " + markdownEscape(p.Anchor)
+		}
+		list = append(list, line+"\n\n"+blocksMarkdown(p.Blocks))
+	}
+	return list
+}
+
+// 按选项提取正文以外的内容
+func (e *wordExtractor) parts(filePath string, doc *document.Document, opts WordOptions) ([]WordPart, error) {
+	var parts []WordPart
+	if opts.HeadersFooters {
+		// 首页、奇偶页页眉内容常常相同，去重
+		seen := map[string]bool{}
+		for _, h := range doc.Headers() {
+			parts = e.appendPart(parts, seen, WordPart{Source: WordSourceHeader}, e.contentBlocks(h.X().EG_ContentBlockContent))
+		}
+		for _, f := range doc.Footers() {
+			parts = e.appendPart(parts, seen, WordPart{Source: WordSourceFooter}, e.contentBlocks(f.X().EG_ContentBlockContent))
+		}
+	}
+	if !opts.Notes && !opts.Comments && !opts.TextBoxes {
+		return parts, nil
+	}
+
+	pkg, err := openOoxml(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer pkg.Close()
+	main := pkg.mainPart("word/document.xml")
+
+	var scan *wordBodyScan
+	if opts.Comments || opts.TextBoxes {
+		if scan, err = scanWordBody(pkg, main); err != nil {
+			return nil, err
+		}
+	}
+
+	if opts.TextBoxes {
+		for _, box := range scan.textBoxes {
+			parts = e.appendPart(parts, nil, WordPart{Source: WordSourceTextBox}, e.blocks(box.EG_BlockLevelElts))
+		}
+	}
+
+	if opts.Notes {
+		var footnotes wml.Footnotes
+		if target := pkg.relTarget(main, relFootnotes); target != "" && pkg.decode(target, &footnotes) == nil {
+			for _, n := range footnotes.Footnote {
+				parts = e.appendNote(parts, WordSourceFootnote, n)
+			}
+		}
+		var endnotes wml.Endnotes
+		if target := pkg.relTarget(main, relEndnotes); target != "" && pkg.decode(target, &endnotes) == nil {
+			for _, n := range endnotes.Endnote {
+				parts = e.appendNote(parts, WordSourceEndnote, n)
+			}
+		}
+	}
+
+	if opts.Comments {
+		var comments wml.Comments
+		if target := pkg.relTarget(main, relComments); target != "" && pkg.decode(target, &comments) == nil {
+			for _, c := range comments.Comment {
+				id := strconv.FormatInt(c.IdAttr, 10)
+				part := WordPart{
+					Source: WordSourceComment,
+					Id:     id,
+					Author: c.AuthorAttr,
+					Date:   c.DateAttr,
+					Anchor: scan.anchor(id),
+				}
+				parts = e.appendPart(parts, nil, part, e.blocks(c.EG_BlockLevelElts))
+			}
+		}
+	}
+	return parts, nil
+}
+
+func (e *wordExtractor) appendNote(parts []WordPart, source string, n *wml.CT_FtnEdn) []WordPart {
+	// 跳过分隔线等特殊脚注
+	if n.TypeAttr != wml.ST_FtnEdnUnset && n.TypeAttr != wml.ST_FtnEdnNormal {
+		return parts
+	}
+	part := WordPart{Source: source, Id: strconv.FormatInt(n.IdAttr, 10)}
+	return e.appendPart(parts, nil, part, e.blocks(n.EG_BlockLevelElts))
+}
+
+// 填充内容后追加，空内容和 seen 中已有的内容跳过
+func (e *wordExtractor) appendPart(parts []WordPart, seen map[string]bool, part WordPart, blocks []WordBlock) []WordPart {
+	part.Blocks = blocks
+	part.Text = blocksText(blocks)
+	if strings.TrimSpace(part.Text) == "" {
+		return parts
+	}
+	if seen != nil {
+		key := part.Source + "\x00" + part.Text
+		if seen[key] {
+			return parts
+		}
+		seen[key] = true
+	}
+	return append(parts, part)
+}
+
+// 正文 xml 扫描结果，gooxml 不解析文本框和批注范围，直接按 xml 读取
+type wordBodyScan struct {
+	anchors   map[string]*strings.Builder // 批注编号 -> 批注范围内的文字
+	textBoxes []*wml.CT_TxbxContent
+}
+
+func (s *wordBodyScan) anchor(id string) string {
+	if s == nil || s.anchors[id] == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(s.anchors[id].String()), " ")
+}
+
+func scanWordBody(pkg *ooxmlPackage, part string) (*wordBodyScan, error) {
+	rc, err := pkg.open(part)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	scan := &wordBodyScan{anchors: map[string]*strings.Builder{}}
+	open := map[string]bool{}
+	inText := false
+	d := xml.NewDecoder(rc)
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch {
+			case t.Name.Space == nsMc && t.Name.Local == "Fallback":
+				// 兼容旧版本的重复内容，Choice 中已经读取过
+				if err := d.Skip(); err != nil {
+					return nil, err
+				}
+			case t.Name.Space != nsWord:
+			case t.Name.Local == "commentRangeStart":
+				id := xmlAttr(t, "id")
+				open[id] = true
+				scan.anchors[id] = &strings.Builder{}
+			case t.Name.Local == "commentRangeEnd":
+				delete(open, xmlAttr(t, "id"))
+			case t.Name.Local == "t":
+				inText = true
+			case t.Name.Local == "tab", t.Name.Local == "br":
+				for id := range open {
+					scan.anchors[id].WriteString(" ")
+				}
+			case t.Name.Local == "txbxContent":
+				box := &wml.CT_TxbxContent{}
+				if err := d.DecodeElement(box, &t); err != nil {
+					return nil, err
+				}
+				scan.textBoxes = append(scan.textBoxes, box)
+			}
+		case xml.EndElement:
+			if t.Name.Space == nsWord && t.Name.Local == "t" {
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				for id := range open {
+					scan.anchors[id].Write(t)
+				}
+			}
+		}
+	}
+	return scan, nil
+}
+
+func xmlAttr(t xml.StartElement, local string) string {
+	for _, a := range t.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,125 @@
+package office
+
+import (
+	"strings"
+	"time"
+
+	"baliance.com/gooxml/schema/soo/wml"
+)
+
+// 修订处理方式
+type WordRevisionMode int
+
+const (
+	// 接受所有修订：保留插入，丢弃删除
+	WordRevisionAccept WordRevisionMode = iota
+	// 拒绝所有修订：丢弃插入，保留删除
+	WordRevisionReject
+	// 标注修订：插入和删除都保留并标记，同时返回修订列表
+	WordRevisionAnnotate
+)
+
+// 修订类型
+const (
+	WordRevisionInsert = "insert"
+	WordRevisionDelete = "delete"
+)
+
+// 一处修订，对应一个 w:ins / w:del（移动视为删除加插入）
+type WordRevision struct {
+	Type   string     `json:"type"` // WordRevisionInsert 或 WordRevisionDelete
+	Author string     `json:"author,omitempty"`
+	Date   *time.Time `json:"date,omitempty"`
+	Text   string     `json:"text"`
+}
+
+// 标注模式下片段的纯文本，插入为 {+文字+}，删除为 [-文字-]
+func (s WordSpan) text() string {
+	switch s.Revision {
+	case WordRevisionInsert:
+		return "{+" + s.Text + "+}"
+	case WordRevisionDelete:
+		return "[-" + s.Text + "-]"
+	}
+	return s.Text
+}
+
+func (r WordRevision) label() string {
+	label := "插入"
+	if r.Type == WordRevisionDelete {
+		label = "删除"
+	}
+	if r.Author != "" {
+		label += " " + r.Author
+	}
+	if r.Date != nil {
+		label += " " + r.Date.Format("2006-01-02 15:04")
+	}
+	return "[" + label + "]"
+}
+
+func revisionsText(revisions []WordRevision) []string {
+	lines := make([]string, 0, len(revisions))
+	for _, r := range revisions {
+		lines = append(lines, r.label()+" "+r.Text)
+	}
+	return lines
+}
+
+func revisionsMarkdown(revisions []WordRevision) []string {
+	lines := make([]string, 0, len(revisions))
+	for _, r := range revisions {
+		lines = append(lines, "- **"+markdownEscape(r.label())+"** "+markdownEscape(r.Text))
+	}
+	return lines
+}
+
+// 段落中的修订元素
+func (e *wordExtractor) runLevel(rle *wml.EG_RunLevelElements) []WordSpan {
+	var spans []WordSpan
+	spans = append(spans, e.trackChange(rle.Ins, WordRevisionInsert)...)
+	spans = append(spans, e.trackChange(rle.MoveTo, WordRevisionInsert)...)
+	spans = append(spans, e.trackChange(rle.Del, WordRevisionDelete)...)
+	spans = append(spans, e.trackChange(rle.MoveFrom, WordRevisionDelete)...)
+	return spans
+}
+
+// 按修订模式决定是否保留修订中的文字
+func (e *wordExtractor) trackChange(tc *wml.CT_RunTrackChange, kind string) []WordSpan {
+	if tc == nil {
+		return nil
+	}
+	switch e.revisionMode {
+	case WordRevisionAccept:
+		if kind == WordRevisionDelete {
+			return nil
+		}
+	case WordRevisionReject:
+		if kind == WordRevisionInsert {
+			return nil
+		}
+	}
+
+	var spans []WordSpan
+	for _, crc := range tc.EG_ContentRunContent {
+		spans = append(spans, e.contentRun(crc)...)
+	}
+	if e.revisionMode != WordRevisionAnnotate {
+		return spans
+	}
+
+	var sb strings.Builder
+	for i := range spans {
+		spans[i].Revision = kind
+		sb.WriteString(spans[i].Text)
+	}
+	if sb.Len() > 0 {
+		e.revisions = append(e.revisions, WordRevision{
+			Type:   kind,
+			Author: tc.AuthorAttr,
+			Date:   tc.DateAttr,
+			Text:   sb.String(),
+		})
+	}
+	return spans
+}
@@ -0,0 +1,183 @@
+package office
+
+import (
+	"strings"
+
+	"baliance.com/gooxml/schema/soo/wml"
+)
+
+// word表格
+type WordTable struct {
+	Rows []WordRow `json:"rows"`
+	Cols int       `json:"cols"` // 网格列数，合并单元格按网格展开后的列数
+}
+
+// 表格行
+type WordRow struct {
+	Cells []WordCell `json:"cells"`
+}
+
+// 表格单元格
+type WordCell struct {
+	Text    string      `json:"text"`
+	Blocks  []WordBlock `json:"blocks,omitempty"` // 单元格中的段落和嵌套表格
+	Col     int         `json:"col"`              // 起始网格列，从 0 开始
+	ColSpan int         `json:"col_span"`
+	RowSpan int         `json:"row_span"`
+	Merged  bool        `json:"merged,omitempty"` // 被上方单元格纵向合并，内容在合并起始单元格中
+}
+
+// 按网格展开的二维文本，合并区域内每格都填入起始单元格的内容
+func (t *WordTable) Grid() [][]string {
+	grid := make([][]string, len(t.Rows))
+	for i := range grid {
+		grid[i] = make([]string, t.Cols)
+	}
+	for r, row := range t.Rows {
+		for _, cell := range row.Cells {
+			if cell.Merged {
+				continue
+			}
+			for i := r; i < r+cell.RowSpan && i < len(grid); i++ {
+				for j := cell.Col; j < cell.Col+cell.ColSpan && j < t.Cols; j++ {
+					grid[i][j] = cell.Text
+				}
+			}
+		}
+	}
+	return grid
+}
+
+// 纯文本，每行一行，单元格以制表符分隔
+func (t *WordTable) Text() string {
+	lines := make([]string, 0, len(t.Rows))
+	for _, row := range t.Rows {
+		cells := make([]string, 0, len(row.Cells))
+		for _, cell := range row.Cells {
+			text := ""
+			if !cell.Merged {
+				text = strings.Join(strings.Fields(cell.Text), " ")
+			}
+			cells = append(cells, text)
+		}
+		lines = append(lines, strings.Join(cells, "\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Markdown 管道表格，第一行作为表头，合并区域只在起始单元格显示内容
+func (t *WordTable) Markdown() string {
+	grid := make([][]string, len(t.Rows))
+	for r, row := range t.Rows {
+		grid[r] = make([]string, t.Cols)
+		for _, cell := range row.Cells {
+			if !cell.Merged && cell.Col < t.Cols {
+				grid[r][cell.Col] = strings.ReplaceAll(markdownEscape(cell.Text), "\n", "<br>")
+			}
+		}
+	}
+
+	var sb strings.Builder
+	for r, cells := range grid {
+		if r > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("| " + strings.Join(cells, " | ") + " |")
+		if r == 0 {
+			sb.WriteString("\n|" + strings.Repeat(" --- |", t.Cols))
+		}
+	}
+	return sb.String()
+}
+
+// 单元格在表格中的位置
+type cellPos struct {
+	row, cell int
+}
+
+func (e *wordExtractor) table(tbl *wml.CT_Tbl) *WordTable {
+	t := &WordTable{}
+	// 网格列 -> 正在纵向合并的起始单元格
+	vMerge := map[int]cellPos{}
+
+	for _, tr := range tableRows(tbl) {
+		r := len(t.Rows)
+		row := WordRow{}
+		col := 0
+		for _, tc := range rowCells(tr) {
+			span := 1
+			var pr *wml.CT_TcPr
+			if tc.TcPr != nil {
+				pr = tc.TcPr
+				if pr.GridSpan != nil && pr.GridSpan.ValAttr > 1 {
+					span = int(pr.GridSpan.ValAttr)
+				}
+			}
+
+			// 旧式横向合并，并入左侧单元格
+			if pr != nil && pr.HMerge != nil && pr.HMerge.ValAttr != wml.ST_MergeRestart && len(row.Cells) > 0 {
+				row.Cells[len(row.Cells)-1].ColSpan += span
+				col += span
+				continue
+			}
+
+			cell := WordCell{Col: col, ColSpan: span, RowSpan: 1}
+			// vMerge 不带 val 时表示继续合并
+			if pr != nil && pr.VMerge != nil && pr.VMerge.ValAttr != wml.ST_MergeRestart {
+				if origin, ok := vMerge[col]; ok {
+					t.Rows[origin.row].Cells[origin.cell].RowSpan++
+					cell.Merged = true
+					cell.RowSpan = 0
+				}
+			}
+			if !cell.Merged {
+				cell.Blocks = e.blocks(tc.EG_BlockLevelElts)
+				cell.Text = blocksText(cell.Blocks)
+				for i := col; i < col+span; i++ {
+					delete(vMerge, i)
+				}
+				if pr != nil && pr.VMerge != nil && pr.VMerge.ValAttr == wml.ST_MergeRestart {
+					vMerge[col] = cellPos{row: r, cell: len(row.Cells)}
+				}
+			}
+			row.Cells = append(row.Cells, cell)
+			col += span
+		}
+		if col > t.Cols {
+			t.Cols = col
+		}
+		t.Rows = append(t.Rows, row)
+	}
+	if len(t.Rows) == 0 {
+		return nil
+	}
+	return t
+}
+
+// 表格中的所有行，包括内容控件中的行
+func tableRows(tbl *wml.CT_Tbl) []*wml.CT_Row {
+	var rows []*wml.CT_Row
+	for _, rc := range tbl.EG_ContentRowContent {
+		rows = append(rows, rc.Tr...)
+		if rc.Sdt != nil && rc.Sdt.SdtContent != nil {
+			for _, sub := range rc.Sdt.SdtContent.EG_ContentRowContent {
+				rows = append(rows, sub.Tr...)
+			}
+		}
+	}
+	return rows
+}
+
+// 行中的所有单元格，包括内容控件中的单元格
+func rowCells(tr *wml.CT_Row) []*wml.CT_Tc {
+	var cells []*wml.CT_Tc
+	for _, cc := range tr.EG_ContentCellContent {
+		cells = append(cells, cc.Tc...)
+		if cc.Sdt != nil && cc.Sdt.SdtContent != nil {
+			for _, sub := range cc.Sdt.SdtContent.EG_ContentCellContent {
+				cells = append(cells, sub.Tc...)
+			}
+		}
+	}
+	return cells
+}
@@ -0,0 +1,49 @@
+package office
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	cases := []struct {
+		n      int
+		format string
+		want   string
+	}{
+		{1, "lowerLetter", "a"},
+		{28, "upperLetter", "BB"},
+		{4, "lowerRoman", "iv"},
+		{1994, "upperRoman", "MCMXCIV"},
+		{12, "chineseCounting", "十二"},
+		{3, "decimalEnclosedCircle", "③"},
+		{0, "lowerLetter", "0"},
+		{-3, "upperLetter", "0"},
+		{5000, "upperRoman", "5000"},
+		{9e18, "lowerLetter", strconv.Itoa(maxListNumber)},
+	}
+	for _, c := range cases {
+		if got := formatNumber(c.n, c.format); got != c.want {
+			t.Errorf("formatNumber(%d, %s) = %q, want %q", c.n, c.format, got, c.want)
+		}
+	}
+}
+
+func TestNextNumberStart(t *testing.T) {
+	// 负数和极大的起始值，计数不超过上限
+	e := &wordExtractor{
+		numbering: map[int64][]numberingLevel{
+			1: {{format: "lowerLetter", text: "%1)", start: -5}},
+			2: {{format: "upperRoman", text: "%1.", start: 9e18}},
+		},
+		counters: map[int64][]int{},
+	}
+	for _, c := range []struct {
+		numId int64
+		want  string
+	}{{1, "0)"}, {2, "32767."}, {2, "32767."}} {
+		if got, _ := e.nextNumber(c.numId, 0); got != c.want {
+			t.Errorf("numId %d: %q, want %q", c.numId, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"io"
+	"io/ioutil"
+)
+
+var errUnsupportedFilter = errors.New("pdf: unsupported stream filter")
+
+// 单个流解压后的最大字节数，防止压缩炸弹耗尽内存
+const maxDecodedSize = 64 << 20
+
+// 按 Filter 依次解码流数据
+func (r *Reader) decodeStream(s *stream) ([]byte, error) {
+	data := s.data
+	var filters array
+	var params array
+	switch f := r.resolve(s.hdr["Filter"]).(type) {
+	case name:
+		filters = array{f}
+		params = array{r.resolve(s.hdr["DecodeParms"])}
+	case array:
+		filters = f
+		params, _ = r.resolve(s.hdr["DecodeParms"]).(array)
+	}
+
+	for i, f := range filters {
+		var parm dict
+		if i < len(params) {
+			parm, _ = r.resolve(params[i]).(dict)
+		}
+		var err error
+		switch r.resolve(f) {
+		case name("FlateDecode"), name("Fl"):
+			data, err = flateDecode(data, parm)
+		case name("ASCIIHexDecode"), name("AHx"):
+			data = asciiHexDecode(data)
+		case name("ASCII85Decode"), name("A85"):
+			data = ascii85Decode(data)
+		default:
+			// 图片类编码（DCT/JPX/CCITT 等）无需解码
+			return nil, errUnsupportedFilter
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+func flateDecode(data []byte, parm dict) ([]byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	// 截断的流尽量返回已解出的部分，超出上限的部分丢弃
+	out, err := ioutil.ReadAll(io.LimitReader(zr, maxDecodedSize))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+
+	predictor, _ := toInt(parm["Predictor"])
+	if predictor < 10 {
+		return out, nil
+	}
+	columns, ok := toInt(parm["Columns"])
+	if !ok || columns <= 0 {
+		columns = 1
+	}
+	colors, ok := toInt(parm["Colors"])
+	if !ok || colors <= 0 {
+		colors = 1
+	}
+	bpc, ok := toInt(parm["BitsPerComponent"])
+	if !ok || bpc <= 0 {
+		bpc = 8
+	}
+	return pngUnpredict(out, columns*colors*bpc/8, maxInt(colors*bpc/8, 1)), nil
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// PNG 预测器还原
+func pngUnpredict(data []byte, rowLen int, bpp int) []byte {
+	// 行宽来自参数，数据不足一行时原样返回
+	if rowLen <= 0 || rowLen+1 > len(data) {
+		return data
+	}
+	var out []byte
+	prev := make([]byte, rowLen)
+	for pos := 0; pos+rowLen+1 <= len(data); pos += rowLen + 1 {
+		kind := data[pos]
+		row := make([]byte, rowLen)
+		copy(row, data[pos+1:pos+1+rowLen])
+		for i := 0; i < rowLen; i++ {
+			var left, upLeft byte
+			if i >= bpp {
+				left = row[i-bpp]
+				upLeft = prev[i-bpp]
+			}
+			up := prev[i]
+			switch kind {
+			case 1:
+				row[i] += left
+			case 2:
+				row[i] += up
+			case 3:
+				row[i] += byte((int(left) + int(up)) / 2)
+			case 4:
+				row[i] += paeth(left, up, upLeft)
+			}
+		}
+		out = append(out, row...)
+		prev = row
+	}
+	return out
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func asciiHexDecode(data []byte) []byte {
+	// data 可能是文件缓冲区的切片，追加时不能写入其后的字节
+	p := newParser(append(data[:len(data):len(data)], '>'), 0, false)
+	return p.readHexString()
+}
+
+func ascii85Decode(data []byte) []byte {
+	var out []byte
+	var group [5]byte
+	n := 0
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		if c == '~' {
+			break
+		}
+		if isSpace(c) {
+			continue
+		}
+		if c == 'z' && n == 0 {
+			out = append(out, 0, 0, 0, 0)
+			continue
+		}
+		if c < '!' || c > 'u' {
+			continue
+		}
+		group[n] = c - '!'
+		n++
+		if n == 5 {
+			out = append(out, decode85(group, 4)...)
+			n = 0
+		}
+	}
+	if n > 1 {
+		for i := n; i < 5; i++ {
+			group[i] = 'u' - '!'
+		}
+		out = append(out, decode85(group, n-1)...)
+	}
+	return out
+}
+
+func decode85(group [5]byte, n int) []byte {
+	var v uint32
+	for _, b := range group {
+		v = v*85 + uint32(b)
+	}
+	buf := []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+	return buf[:n]
+}
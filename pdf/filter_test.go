@@ -0,0 +1,61 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"testing"
+)
+
+func TestPngUnpredict(t *testing.T) {
+	// 两行，每行 3 字节：第一行 Sub，第二行 Up
+	data := []byte{1, 1, 1, 1, 2, 1, 2, 3}
+	got := pngUnpredict(data, 3, 1)
+	if want := []byte{1, 2, 3, 2, 4, 6}; !bytes.Equal(got, want) {
+		t.Fatalf("pngUnpredict = %v, want %v", got, want)
+	}
+}
+
+func TestPngUnpredictShort(t *testing.T) {
+	// 行宽远大于数据时不应按行宽分配
+	data := []byte{2, 1, 2, 3}
+	for _, rowLen := range []int{1 << 40, 4, 0, -8} {
+		if got := pngUnpredict(data, rowLen, 1); !bytes.Equal(got, data) {
+			t.Errorf("rowLen %d: pngUnpredict = %v", rowLen, got)
+		}
+	}
+}
+
+func TestASCIIHexDecode(t *testing.T) {
+	// 解码不能改写切片之后的字节
+	buf := []byte("48 6 5 6c6c6F  x")
+	got := asciiHexDecode(buf[:len(buf)-2])
+	if string(got) != "Hello" {
+		t.Fatalf("asciiHexDecode = %q", got)
+	}
+	if string(buf) != "48 6 5 6c6c6F  x" {
+		t.Fatalf("buffer = %q", buf)
+	}
+	if got := asciiHexDecode([]byte("414")); string(got) != "A@" {
+		t.Fatalf("odd digits = %q", got)
+	}
+}
+
+func TestASCII85Decode(t *testing.T) {
+	if got := ascii85Decode([]byte("87cURD]i,\"Ebo80~>")); string(got) != "Hello World!" {
+		t.Fatalf("ascii85Decode = %q", got)
+	}
+	if got := ascii85Decode([]byte("z87c")); !bytes.Equal(got, []byte{0, 0, 0, 0, 'H', 'e'}) {
+		t.Fatalf("ascii85Decode = %q", got)
+	}
+}
+
+func TestFlateDecodeLimit(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zlib.NewWriter(&buf)
+	zw.Write(make([]byte, maxDecodedSize+1024))
+	zw.Close()
+	out, err := flateDecode(buf.Bytes(), nil)
+	if err != nil || len(out) != maxDecodedSize {
+		t.Fatalf("len = %d, err = %v", len(out), err)
+	}
+}
@@ -0,0 +1,359 @@
+package pdf
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+	"golang.org/x/text/encoding/traditionalchinese"
+)
+
+// 字体：把字符串中的字符码转换为 Unicode 文本
+type font struct {
+	toUnicode *cmap
+	// 复合字体（Type0）默认双字节编码
+	composite bool
+	// 预定义 CMap 对应的解码器，如 GBK-EUC-H
+	decoder *encoding.Decoder
+	utf16   bool
+	simple  [256]rune
+}
+
+func (r *Reader) loadFont(d dict) *font {
+	f := &font{}
+	subtype, _ := r.resolve(d["Subtype"]).(name)
+	f.composite = subtype == "Type0"
+	f.simple = standardEncoding
+
+	if s, ok := r.resolve(d["ToUnicode"]).(*stream); ok {
+		if data, err := r.decodeStream(s); err == nil {
+			f.toUnicode = parseCMap(data)
+		}
+	}
+
+	switch enc := r.resolve(d["Encoding"]).(type) {
+	case name:
+		f.applyEncodingName(enc)
+	case dict:
+		if base, ok := r.resolve(enc["BaseEncoding"]).(name); ok {
+			f.applyEncodingName(base)
+		}
+		if diffs, ok := r.resolve(enc["Differences"]).(array); ok {
+			code := 0
+			for _, item := range diffs {
+				switch v := r.resolve(item).(type) {
+				case int64:
+					code = int(v)
+				case name:
+					if code >= 0 && code < 256 {
+						if ch, ok := glyphRune(string(v)); ok {
+							f.simple[code] = ch
+						}
+					}
+					code++
+				}
+			}
+		}
+	}
+	return f
+}
+
+func (f *font) applyEncodingName(enc name) {
+	s := string(enc)
+	switch {
+	case s == "WinAnsiEncoding":
+		f.simple = winAnsiEncoding
+	case s == "MacRomanEncoding":
+		f.simple = macRomanEncoding
+	case s == "StandardEncoding":
+		f.simple = standardEncoding
+	case strings.HasPrefix(s, "Uni") && strings.Contains(s, "UCS2"):
+		f.utf16 = true
+	case strings.HasPrefix(s, "Uni") && strings.Contains(s, "UTF16"):
+		f.utf16 = true
+	case strings.HasPrefix(s, "GB"):
+		f.decoder = simplifiedchinese.GB18030.NewDecoder()
+	case strings.HasPrefix(s, "B5") || strings.HasPrefix(s, "ETen") || strings.HasPrefix(s, "HKscs"):
+		f.decoder = traditionalchinese.Big5.NewDecoder()
+	}
+}
+
+// 解码字符串，无法映射的字符码直接丢弃
+func (f *font) decode(s []byte) string {
+	if f.toUnicode != nil {
+		return f.toUnicode.decode(s, f.composite)
+	}
+	if f.composite {
+		switch {
+		case f.utf16:
+			u := make([]uint16, 0, len(s)/2)
+			for i := 0; i+1 < len(s); i += 2 {
+				u = append(u, uint16(s[i])<<8|uint16(s[i+1]))
+			}
+			return string(utf16.Decode(u))
+		case f.decoder != nil:
+			out, err := f.decoder.Bytes(s)
+			if err != nil {
+				return ""
+			}
+			return string(out)
+		}
+		// Identity-H 且没有 ToUnicode，无法还原文本
+		return ""
+	}
+	var sb strings.Builder
+	for _, b := range s {
+		if ch := f.simple[b]; ch != 0 {
+			sb.WriteRune(ch)
+		}
+	}
+	return sb.String()
+}
+
+type codeRange struct {
+	lo, hi []byte
+}
+
+type bfRange struct {
+	lo, hi []byte
+	dst    []byte
+	dsts   [][]byte
+}
+
+// ToUnicode CMap
+type cmap struct {
+	space  []codeRange
+	chars  map[string]string
+	ranges []bfRange
+}
+
+func parseCMap(data []byte) *cmap {
+	c := &cmap{chars: map[string]string{}}
+	p := newParser(data, 0, false)
+	var stack []object
+	for !p.eof() {
+		obj, err := p.readObject()
+		if err != nil {
+			break
+		}
+		kw, ok := obj.(keyword)
+		if !ok {
+			stack = append(stack, obj)
+			continue
+		}
+		switch kw {
+		case "endcodespacerange":
+			for i := 0; i+1 < len(stack); i += 2 {
+				lo, _ := stack[i].([]byte)
+				hi, _ := stack[i+1].([]byte)
+				if len(lo) > 0 && len(lo) == len(hi) {
+					c.space = append(c.space, codeRange{lo: lo, hi: hi})
+				}
+			}
+		case "endbfchar":
+			for i := 0; i+1 < len(stack); i += 2 {
+				src, _ := stack[i].([]byte)
+				switch dst := stack[i+1].(type) {
+				case []byte:
+					c.chars[string(src)] = utf16BE(dst)
+				case name:
+					if ch, ok := glyphRune(string(dst)); ok {
+						c.chars[string(src)] = string(ch)
+					}
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(stack); i += 3 {
+				lo, _ := stack[i].([]byte)
+				hi, _ := stack[i+1].([]byte)
+				if len(lo) == 0 || len(lo) != len(hi) {
+					continue
+				}
+				br := bfRange{lo: lo, hi: hi}
+				switch dst := stack[i+2].(type) {
+				case []byte:
+					br.dst = dst
+				case array:
+					for _, item := range dst {
+						b, _ := item.([]byte)
+						br.dsts = append(br.dsts, b)
+					}
+				}
+				c.ranges = append(c.ranges, br)
+			}
+		}
+		stack = stack[:0]
+	}
+	return c
+}
+
+func (c *cmap) decode(s []byte, composite bool) string {
+	var sb strings.Builder
+	for len(s) > 0 {
+		n := c.codeLen(s, composite)
+		if n > len(s) {
+			n = len(s)
+		}
+		sb.WriteString(c.lookup(s[:n]))
+		s = s[n:]
+	}
+	return sb.String()
+}
+
+// 按 codespacerange 判断字符码长度
+func (c *cmap) codeLen(s []byte, composite bool) int {
+	for n := 1; n <= 4 && n <= len(s); n++ {
+		for _, r := range c.space {
+			if len(r.lo) == n && inRange(s[:n], r.lo, r.hi) {
+				return n
+			}
+		}
+	}
+	if composite {
+		return 2
+	}
+	return 1
+}
+
+// 逐字节比较，codespacerange 中每个字节独立取值范围
+func inRange(code, lo, hi []byte) bool {
+	for i := range code {
+		if code[i] < lo[i] || code[i] > hi[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *cmap) lookup(code []byte) string {
+	if s, ok := c.chars[string(code)]; ok {
+		return s
+	}
+	for _, r := range c.ranges {
+		if len(r.lo) != len(code) || bytes.Compare(code, r.lo) < 0 || bytes.Compare(code, r.hi) > 0 {
+			continue
+		}
+		offset := codeValue(code) - codeValue(r.lo)
+		if r.dsts != nil {
+			if offset < len(r.dsts) {
+				return utf16BE(r.dsts[offset])
+			}
+			return ""
+		}
+		dst := make([]byte, len(r.dst))
+		copy(dst, r.dst)
+		// 目标值最后一个字节递增
+		for i := len(dst) - 1; i >= 0 && offset > 0; i-- {
+			v := int(dst[i]) + offset
+			dst[i] = byte(v)
+			offset = v >> 8
+		}
+		return utf16BE(dst)
+	}
+	return ""
+}
+
+func codeValue(b []byte) int {
+	v := 0
+	for _, c := range b {
+		v = v<<8 | int(c)
+	}
+	return v
+}
+
+func utf16BE(b []byte) string {
+	if len(b) == 1 {
+		return string(rune(b[0]))
+	}
+	u := make([]uint16, 0, len(b)/2)
+	for i := 0; i+1 < len(b); i += 2 {
+		u = append(u, uint16(b[i])<<8|uint16(b[i+1]))
+	}
+	return string(utf16.Decode(u))
+}
+
+// 字形名转 Unicode
+func glyphRune(g string) (rune, bool) {
+	if ch, ok := glyphNames[g]; ok {
+		return ch, true
+	}
+	if len(g) == 1 {
+		return rune(g[0]), true
+	}
+	// 去掉 ".sc" 等后缀
+	if i := strings.IndexByte(g, '.'); i > 0 {
+		return glyphRune(g[:i])
+	}
+	if strings.HasPrefix(g, "uni") && len(g) >= 7 {
+		if v, err := strconv.ParseUint(g[3:7], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	if strings.HasPrefix(g, "u") && len(g) >= 5 && len(g) <= 7 {
+		if v, err := strconv.ParseUint(g[1:], 16, 32); err == nil {
+			return rune(v), true
+		}
+	}
+	return 0, false
+}
+
+var glyphNames = map[string]rune{
+	"space": ' ', "exclam": '!', "quotedbl": '"', "numbersign": '#', "dollar": '$',
+	"percent": '%', "ampersand": '&', "quotesingle": '\'', "quoteright": '’',
+	"parenleft": '(', "parenright": ')', "asterisk": '*', "plus": '+', "comma": ',',
+	"hyphen": '-', "minus": '−', "period": '.', "slash": '/',
+	"zero": '0', "one": '1', "two": '2', "three": '3', "four": '4',
+	"five": '5', "six": '6', "seven": '7', "eight": '8', "nine": '9',
+	"colon": ':', "semicolon": ';', "less": '<', "equal": '=', "greater": '>',
+	"question": '?', "at": '@', "bracketleft": '[', "backslash": '\\',
+	"bracketright": ']', "asciicircum": '^', "underscore": '_', "grave": '`',
+	"quoteleft": '‘', "braceleft": '{', "bar": '|', "braceright": '}',
+	"asciitilde": '~', "bullet": '•', "endash": '–', "emdash": '—',
+	"quotedblleft": '“', "quotedblright": '”', "quotesinglbase": '‚',
+	"quotedblbase": '„', "ellipsis": '…', "dagger": '†',
+	"daggerdbl": '‡', "perthousand": '‰', "trademark": '™',
+	"copyright": '©', "registered": '®', "degree": '°',
+	"section": '§', "paragraph": '¶', "periodcentered": '·',
+	"multiply": '×', "divide": '÷', "plusminus": '±',
+	"fi": 'ﬁ', "fl": 'ﬂ', "nbspace": ' ', "euro": '€',
+	"sterling": '£', "yen": '¥', "cent": '¢',
+}
+
+var standardEncoding = func() (t [256]rune) {
+	for i := 32; i < 127; i++ {
+		t[i] = rune(i)
+	}
+	t['\''] = '’'
+	t['`'] = '‘'
+	return
+}()
+
+var winAnsiEncoding = func() (t [256]rune) {
+	for i := 32; i < 127; i++ {
+		t[i] = rune(i)
+	}
+	high := [32]rune{
+		'€', 0, '‚', 'ƒ', '„', '…', '†', '‡',
+		'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+		0, '‘', '’', '“', '”', '•', '–', '—',
+		'˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+	}
+	copy(t[128:160], high[:])
+	for i := 160; i < 256; i++ {
+		t[i] = rune(i)
+	}
+	return
+}()
+
+var macRomanEncoding = func() (t [256]rune) {
+	for i := 32; i < 127; i++ {
+		t[i] = rune(i)
+	}
+	high := []rune("ÄÅÇÉÑÖÜáàâäãåçéèêëíìîïñóòôöõúùûü†°¢£§•¶ß®©™´¨≠ÆØ∞±≤≥¥µ∂∑∏π∫ªºΩæø" +
+		"¿¡¬√ƒ≈∆«»…\u00a0ÀÃÕŒœ–—“”‘’÷◊ÿŸ⁄€‹›ﬁﬂ‡·‚„‰ÂÊÁËÈÍÎÏÌÓÔ\uf8ffÒÚÛÙıˆ˜¯˘˙˚¸˝˛ˇ")
+	copy(t[128:], high)
+	return
+}()
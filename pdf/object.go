@@ -0,0 +1,402 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+)
+
+// pdf 基础对象类型
+type object interface{}
+
+type name string
+
+type keyword string
+
+type array []object
+
+type dict map[name]object
+
+type objRef struct {
+	num int
+	gen int
+}
+
+type stream struct {
+	hdr  dict
+	data []byte
+}
+
+var errEOF = errors.New("pdf: unexpected end of data")
+
+// 词法/语法解析器，同时用于文件对象和页面内容流
+type parser struct {
+	data []byte
+	pos  int
+	// 内容流中不存在间接引用
+	allowRef bool
+	// 当前数组/字典的嵌套层数
+	depth int
+}
+
+func newParser(data []byte, pos int, allowRef bool) *parser {
+	return &parser{data: data, pos: pos, allowRef: allowRef}
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelim(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+// 跳过空白和注释
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isSpace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		break
+	}
+}
+
+func (p *parser) eof() bool {
+	p.skipSpace()
+	return p.pos >= len(p.data)
+}
+
+// 读取下一个对象，遇到数组/字典结束符时返回对应 keyword
+func (p *parser) readObject() (object, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, errEOF
+	}
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		p.pos++
+		return p.readName(), nil
+	case c == '(':
+		p.pos++
+		return p.readLiteralString(), nil
+	case c == '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return p.readDict()
+		}
+		p.pos++
+		return p.readHexString(), nil
+	case c == '>':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '>' {
+			p.pos += 2
+			return keyword(">>"), nil
+		}
+		p.pos++
+		return keyword(">"), nil
+	case c == '[':
+		p.pos++
+		return p.readArray()
+	case c == ']':
+		p.pos++
+		return keyword("]"), nil
+	case c == '{' || c == '}':
+		p.pos++
+		return keyword(string(c)), nil
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		return p.readNumber()
+	}
+
+	start := p.pos
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelim(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		// 非法字符，跳过避免死循环
+		p.pos++
+		return keyword(string(c)), nil
+	}
+	word := string(p.data[start:p.pos])
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	return keyword(word), nil
+}
+
+func (p *parser) readName() name {
+	var buf []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isSpace(c) || isDelim(c) {
+			break
+		}
+		if c == '#' && p.pos+2 < len(p.data) {
+			if v, err := strconv.ParseUint(string(p.data[p.pos+1:p.pos+3]), 16, 8); err == nil {
+				buf = append(buf, byte(v))
+				p.pos += 3
+				continue
+			}
+		}
+		buf = append(buf, c)
+		p.pos++
+	}
+	return name(buf)
+}
+
+func (p *parser) readLiteralString() []byte {
+	var buf []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return buf
+			}
+		case '\\':
+			if p.pos >= len(p.data) {
+				return buf
+			}
+			c = p.data[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				// 续行
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && p.pos < len(p.data); i++ {
+						d := p.data[p.pos]
+						if d < '0' || d > '7' {
+							break
+						}
+						v = v*8 + int(d-'0')
+						p.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		buf = append(buf, c)
+	}
+	return buf
+}
+
+func (p *parser) readHexString() []byte {
+	var buf []byte
+	var hi byte
+	odd := false
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		if c == '>' {
+			break
+		}
+		v, ok := unhex(c)
+		if !ok {
+			continue
+		}
+		if odd {
+			buf = append(buf, hi<<4|v)
+		} else {
+			hi = v
+		}
+		odd = !odd
+	}
+	if odd {
+		buf = append(buf, hi<<4)
+	}
+	return buf
+}
+
+func unhex(c byte) (byte, bool) {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0', true
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10, true
+	case c >= 'A' && c <= 'F':
+		return c - 'A' + 10, true
+	}
+	return 0, false
+}
+
+func (p *parser) readNumber() (object, error) {
+	start := p.pos
+	isReal := false
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '.' {
+			isReal = true
+		} else if !(c >= '0' && c <= '9') && !((c == '+' || c == '-') && p.pos == start) {
+			break
+		}
+		p.pos++
+	}
+	s := string(p.data[start:p.pos])
+	if isReal {
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0.0, nil
+		}
+		return f, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return int64(0), nil
+	}
+
+	// 尝试识别 "num gen R" 形式的间接引用
+	if p.allowRef && n >= 0 {
+		save := p.pos
+		p.skipSpace()
+		genStart := p.pos
+		for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			p.pos++
+		}
+		if p.pos > genStart {
+			gen, _ := strconv.Atoi(string(p.data[genStart:p.pos]))
+			p.skipSpace()
+			if p.pos < len(p.data) && p.data[p.pos] == 'R' &&
+				(p.pos+1 == len(p.data) || isSpace(p.data[p.pos+1]) || isDelim(p.data[p.pos+1])) {
+				p.pos++
+				return objRef{num: int(n), gen: gen}, nil
+			}
+		}
+		p.pos = save
+	}
+	return n, nil
+}
+
+func (p *parser) readArray() (object, error) {
+	if p.depth >= maxNesting {
+		return nil, ErrInvalid
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	var arr array
+	for {
+		obj, err := p.readObject()
+		if err != nil {
+			return arr, err
+		}
+		if kw, ok := obj.(keyword); ok && kw == "]" {
+			return arr, nil
+		}
+		arr = append(arr, obj)
+	}
+}
+
+func (p *parser) readDict() (object, error) {
+	if p.depth >= maxNesting {
+		return nil, ErrInvalid
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	d := dict{}
+	for {
+		obj, err := p.readObject()
+		if err != nil {
+			return d, err
+		}
+		if kw, ok := obj.(keyword); ok && kw == ">>" {
+			return d, nil
+		}
+		key, ok := obj.(name)
+		if !ok {
+			// 容错：跳过非名称的键
+			continue
+		}
+		val, err := p.readObject()
+		if err != nil {
+			return d, err
+		}
+		if kw, ok := val.(keyword); ok && kw == ">>" {
+			return d, nil
+		}
+		d[key] = val
+	}
+}
+
+// 跳过内联图片 BI ... ID <data> EI
+func (p *parser) skipInlineImage() {
+	// ID 之后紧跟一个空白字符
+	if p.pos < len(p.data) && isSpace(p.data[p.pos]) {
+		p.pos++
+	}
+	for p.pos < len(p.data) {
+		i := bytes.Index(p.data[p.pos:], []byte("EI"))
+		if i < 0 {
+			p.pos = len(p.data)
+			return
+		}
+		end := p.pos + i
+		before := end == 0 || isSpace(p.data[end-1])
+		after := end+2 >= len(p.data) || isSpace(p.data[end+2])
+		p.pos = end + 2
+		if before && after {
+			return
+		}
+	}
+}
+
+func toInt(obj object) (int, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return int(v), true
+	case float64:
+		return int(v), true
+	}
+	return 0, false
+}
+
+func toFloat(obj object) (float64, bool) {
+	switch v := obj.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
@@ -0,0 +1,457 @@
+package pdf
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"regexp"
+	"strconv"
+)
+
+var (
+	ErrEncrypted = errors.New("pdf文件已加密，无法提取文本！")
+	ErrInvalid   = errors.New("不是有效的pdf文件！")
+)
+
+// 引用链或嵌套的最大深度，防止恶意文件造成死循环
+const maxDepth = 32
+
+// 数组/字典的最大嵌套层数，过深的嵌套会耗尽栈空间
+const maxNesting = 256
+
+type xrefEntry struct {
+	offset   int
+	inStream bool
+	stream   int
+	index    int
+}
+
+// pdf 文档读取器
+type Reader struct {
+	data    []byte
+	xref    map[int]xrefEntry
+	trailer dict
+	cache   map[int]object
+	pages   []dict
+}
+
+// 打开本地pdf文件
+func Open(filePath string) (*Reader, error) {
+	data, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return NewReader(data)
+}
+
+// 从内存数据创建读取器
+func NewReader(data []byte) (*Reader, error) {
+	if !bytes.Contains(data[:minInt(len(data), 1024)], []byte("%PDF-")) {
+		return nil, ErrInvalid
+	}
+	r := &Reader{data: data, xref: map[int]xrefEntry{}, cache: map[int]object{}}
+	if err := r.readXref(); err != nil || r.trailer["Root"] == nil {
+		// xref 损坏时扫描全文重建
+		r.xref = map[int]xrefEntry{}
+		r.trailer = nil
+		if err := r.rebuildXref(); err != nil {
+			return nil, err
+		}
+	}
+	if r.trailer["Encrypt"] != nil {
+		return nil, ErrEncrypted
+	}
+
+	root, _ := r.resolve(r.trailer["Root"]).(dict)
+	if root == nil {
+		return nil, ErrInvalid
+	}
+	pages, _ := r.resolve(root["Pages"]).(dict)
+	if pages == nil {
+		return nil, ErrInvalid
+	}
+	r.collectPages(pages, dict{}, 0)
+	return r, nil
+}
+
+// 页数
+func (r *Reader) NumPage() int {
+	return len(r.pages)
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// 解析 startxref 指向的交叉引用表（含 Prev 链）
+func (r *Reader) readXref() error {
+	tail := r.data[len(r.data)-minInt(len(r.data), 2048):]
+	i := bytes.LastIndex(tail, []byte("startxref"))
+	if i < 0 {
+		return ErrInvalid
+	}
+	p := newParser(tail, i+len("startxref"), false)
+	off, ok := toInt(mustObject(p))
+	if !ok {
+		return ErrInvalid
+	}
+
+	seen := map[int]bool{}
+	for depth := 0; off > 0 && off < len(r.data) && !seen[off] && depth < maxDepth; depth++ {
+		seen[off] = true
+		var trailer dict
+		var err error
+		p := newParser(r.data, off, true)
+		p.skipSpace()
+		if bytes.HasPrefix(r.data[p.pos:], []byte("xref")) {
+			p.pos += len("xref")
+			trailer, err = r.readXrefTable(p)
+		} else {
+			trailer, err = r.readXrefStream(off)
+		}
+		if err != nil {
+			return err
+		}
+		if r.trailer == nil {
+			r.trailer = trailer
+		}
+		// 混合型文件，额外的 xref 流
+		if stm, ok := toInt(trailer["XRefStm"]); ok {
+			if _, err := r.readXrefStream(stm); err != nil {
+				return err
+			}
+		}
+		off, ok = toInt(trailer["Prev"])
+		if !ok {
+			break
+		}
+	}
+	if r.trailer == nil {
+		return ErrInvalid
+	}
+	return nil
+}
+
+func mustObject(p *parser) object {
+	obj, _ := p.readObject()
+	return obj
+}
+
+func (r *Reader) readXrefTable(p *parser) (dict, error) {
+	for {
+		obj, err := p.readObject()
+		if err != nil {
+			return nil, err
+		}
+		if kw, ok := obj.(keyword); ok && kw == "trailer" {
+			break
+		}
+		start, ok := toInt(obj)
+		if !ok {
+			return nil, ErrInvalid
+		}
+		count, ok := toInt(mustObject(p))
+		if !ok {
+			return nil, ErrInvalid
+		}
+		for i := 0; i < count; i++ {
+			off, _ := toInt(mustObject(p))
+			mustObject(p)
+			kind, _ := p.readObject()
+			if kw, ok := kind.(keyword); ok && kw == "n" {
+				if _, exists := r.xref[start+i]; !exists {
+					r.xref[start+i] = xrefEntry{offset: off}
+				}
+			}
+		}
+	}
+	trailer, _ := mustObject(p).(dict)
+	if trailer == nil {
+		return nil, ErrInvalid
+	}
+	return trailer, nil
+}
+
+func (r *Reader) readXrefStream(off int) (dict, error) {
+	obj, err := r.readObjectAt(off)
+	if err != nil {
+		return nil, err
+	}
+	s, ok := obj.(*stream)
+	if !ok {
+		return nil, ErrInvalid
+	}
+	data, err := r.decodeStream(s)
+	if err != nil {
+		return nil, err
+	}
+
+	w, _ := r.resolve(s.hdr["W"]).(array)
+	if len(w) != 3 {
+		return nil, ErrInvalid
+	}
+	var widths [3]int
+	rowLen := 0
+	for i := range widths {
+		widths[i], _ = toInt(w[i])
+		// 负宽度会使每行实际读取的字节数超过 rowLen
+		if widths[i] < 0 {
+			return nil, ErrInvalid
+		}
+		rowLen += widths[i]
+	}
+	if rowLen == 0 {
+		return nil, ErrInvalid
+	}
+	index, _ := r.resolve(s.hdr["Index"]).(array)
+	if index == nil {
+		size, _ := toInt(s.hdr["Size"])
+		index = array{int64(0), int64(size)}
+	}
+
+	pos := 0
+	for k := 0; k+1 < len(index); k += 2 {
+		start, _ := toInt(index[k])
+		count, _ := toInt(index[k+1])
+		for i := 0; i < count && pos+rowLen <= len(data); i++ {
+			var fields [3]int
+			for j, n := range widths {
+				for b := 0; b < n; b++ {
+					fields[j] = fields[j]<<8 | int(data[pos])
+					pos++
+				}
+			}
+			// 类型字段缺省为 1
+			if widths[0] == 0 {
+				fields[0] = 1
+			}
+			if _, exists := r.xref[start+i]; exists {
+				continue
+			}
+			switch fields[0] {
+			case 1:
+				r.xref[start+i] = xrefEntry{offset: fields[1]}
+			case 2:
+				r.xref[start+i] = xrefEntry{inStream: true, stream: fields[1], index: fields[2]}
+			}
+		}
+	}
+	return s.hdr, nil
+}
+
+var objHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+// 扫描 "n g obj" 重建交叉引用表
+func (r *Reader) rebuildXref() error {
+	for _, m := range objHeader.FindAllSubmatchIndex(r.data, -1) {
+		num, _ := strconv.Atoi(string(r.data[m[2]:m[3]]))
+		r.xref[num] = xrefEntry{offset: m[0]}
+	}
+	if len(r.xref) == 0 {
+		return ErrInvalid
+	}
+
+	for i := bytes.LastIndex(r.data, []byte("trailer")); i >= 0; {
+		p := newParser(r.data, i+len("trailer"), true)
+		if t, ok := mustObject(p).(dict); ok && t["Root"] != nil {
+			r.trailer = t
+			return nil
+		}
+		i = bytes.LastIndex(r.data[:i], []byte("trailer"))
+	}
+
+	// 没有 trailer 时查找 Catalog 对象
+	for num := range r.xref {
+		obj, err := r.readObjectAt(r.xref[num].offset)
+		if err != nil {
+			continue
+		}
+		var d dict
+		switch v := obj.(type) {
+		case dict:
+			d = v
+		case *stream:
+			d = v.hdr
+		}
+		if d == nil {
+			continue
+		}
+		if d["Root"] != nil {
+			r.trailer = d
+			return nil
+		}
+		if t, _ := d["Type"].(name); t == "Catalog" {
+			r.trailer = dict{"Root": objRef{num: num}}
+			return nil
+		}
+	}
+	return ErrInvalid
+}
+
+// 读取偏移处的 "n g obj ... endobj"
+func (r *Reader) readObjectAt(off int) (object, error) {
+	if off < 0 || off >= len(r.data) {
+		return nil, ErrInvalid
+	}
+	p := newParser(r.data, off, true)
+	for i := 0; i < 3; i++ {
+		if _, err := p.readObject(); err != nil {
+			return nil, err
+		}
+	}
+	obj, err := p.readObject()
+	if err != nil {
+		return nil, err
+	}
+	d, ok := obj.(dict)
+	if !ok {
+		return obj, nil
+	}
+	save := p.pos
+	if kw, _ := mustObject(p).(keyword); kw != "stream" {
+		p.pos = save
+		return d, nil
+	}
+
+	// stream 关键字后为 CRLF 或 LF
+	if p.pos < len(r.data) && r.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(r.data) && r.data[p.pos] == '\n' {
+		p.pos++
+	}
+	start := p.pos
+	length, ok := toInt(r.resolve(d["Length"]))
+	end := start + length
+	if !ok || length < 0 || end > len(r.data) || !bytes.Contains(r.data[end:minInt(len(r.data), end+32)], []byte("endstream")) {
+		i := bytes.Index(r.data[start:], []byte("endstream"))
+		if i < 0 {
+			return nil, ErrInvalid
+		}
+		end = start + i
+		for end > start && (r.data[end-1] == '\n' || r.data[end-1] == '\r') {
+			end--
+		}
+	}
+	return &stream{hdr: d, data: r.data[start:end]}, nil
+}
+
+// 解析间接引用
+func (r *Reader) resolve(obj object) object {
+	for depth := 0; depth < maxDepth; depth++ {
+		ref, ok := obj.(objRef)
+		if !ok {
+			return obj
+		}
+		obj = r.object(ref.num)
+	}
+	return nil
+}
+
+func (r *Reader) object(num int) object {
+	if obj, ok := r.cache[num]; ok {
+		return obj
+	}
+	// 先占位，防止循环引用
+	r.cache[num] = nil
+
+	entry, ok := r.xref[num]
+	if !ok {
+		return nil
+	}
+	var obj object
+	var err error
+	if entry.inStream {
+		obj, err = r.objectFromStream(entry.stream, entry.index)
+	} else {
+		obj, err = r.readObjectAt(entry.offset)
+	}
+	if err != nil {
+		return nil
+	}
+	r.cache[num] = obj
+	return obj
+}
+
+// 从对象流 ObjStm 中读取对象
+func (r *Reader) objectFromStream(streamNum int, index int) (object, error) {
+	s, ok := r.object(streamNum).(*stream)
+	if !ok {
+		return nil, ErrInvalid
+	}
+	data, err := r.decodeStream(s)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := toInt(s.hdr["N"])
+	first, _ := toInt(s.hdr["First"])
+	if index < 0 || index >= n || first < 0 || first > len(data) {
+		return nil, ErrInvalid
+	}
+	p := newParser(data, 0, false)
+	off := 0
+	for i := 0; i <= index; i++ {
+		mustObject(p)
+		off, _ = toInt(mustObject(p))
+	}
+	if off < 0 || first+off >= len(data) {
+		return nil, ErrInvalid
+	}
+	return mustObject(newParser(data, first+off, true)), nil
+}
+
+// 展开页面树，继承 Resources 等属性
+func (r *Reader) collectPages(node dict, inherited dict, depth int) {
+	if depth > maxDepth {
+		return
+	}
+	attrs := dict{}
+	for k, v := range inherited {
+		attrs[k] = v
+	}
+	for _, k := range []name{"Resources", "MediaBox", "Rotate"} {
+		if v, ok := node[k]; ok {
+			attrs[k] = v
+		}
+	}
+
+	kids, _ := r.resolve(node["Kids"]).(array)
+	if t, _ := node["Type"].(name); t == "Page" || (t == "" && kids == nil) {
+		page := dict{}
+		for k, v := range attrs {
+			page[k] = v
+		}
+		for k, v := range node {
+			page[k] = v
+		}
+		r.pages = append(r.pages, page)
+		return
+	}
+	for _, kid := range kids {
+		if d, ok := r.resolve(kid).(dict); ok {
+			r.collectPages(d, attrs, depth+1)
+		}
+	}
+}
+
+// 页面内容流（多个内容流依次拼接）
+func (r *Reader) pageContent(page dict) []byte {
+	var buf bytes.Buffer
+	switch c := r.resolve(page["Contents"]).(type) {
+	case *stream:
+		data, _ := r.decodeStream(c)
+		buf.Write(data)
+	case array:
+		for _, item := range c {
+			if s, ok := r.resolve(item).(*stream); ok {
+				data, _ := r.decodeStream(s)
+				buf.Write(data)
+				buf.WriteByte('\n')
+			}
+		}
+	}
+	return buf.Bytes()
+}
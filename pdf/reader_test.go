@@ -0,0 +1,151 @@
+package pdf
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// 单页 pdf，交叉引用为 ASCIIHex 编码的 xref 流，w 为 /W 数组
+func xrefStreamPdf(w string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /Contents 4 0 R /Resources << /Font << /F1 5 0 R >> >> >>",
+		"<< /Length 27 >>\nstream\nBT /F1 12 Tf (Hello) Tj ET\nendstream",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	offsets := make([]int, len(objs)+1)
+	for i, o := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	offsets[len(objs)] = buf.Len()
+
+	// 1 字节类型、2 字节偏移、1 字节代号，第 0 项为空闲项
+	rows := []byte{0, 0, 0, 0}
+	for _, off := range offsets {
+		rows = append(rows, 1, byte(off>>8), byte(off), 0)
+	}
+	data := hex.EncodeToString(rows)
+	fmt.Fprintf(&buf, "%d 0 obj\n<< /Type /XRef /Size %d /W %s /Root 1 0 R /Filter /ASCIIHexDecode /Length %d >>\nstream\n%s\nendstream\nendobj\n",
+		len(objs)+1, len(objs)+2, w, len(data), data)
+	fmt.Fprintf(&buf, "startxref\n%d\n%%%%EOF\n", offsets[len(objs)])
+	return buf.Bytes()
+}
+
+func TestXrefStream(t *testing.T) {
+	data := xrefStreamPdf("[1 2 1]")
+	r, err := NewReader(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.xref) != 6 || r.xref[4].offset != bytes.Index(data, []byte("4 0 obj")) {
+		t.Fatalf("xref = %v", r.xref)
+	}
+	page, err := r.Page(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Text != "Hello" {
+		t.Fatalf("text = %q", page.Text)
+	}
+}
+
+func TestXrefStreamBadWidths(t *testing.T) {
+	// /W 无效时不读 xref 流，扫描全文重建交叉引用
+	for _, w := range []string{"[-4 4 4]", "[1 -1 4]", "[0 0 0]", "[1 2]"} {
+		data := xrefStreamPdf(w)
+		r := &Reader{data: data, xref: map[int]xrefEntry{}, cache: map[int]object{}}
+		if err := r.readXref(); err != ErrInvalid {
+			t.Errorf("%s: readXref err = %v", w, err)
+		}
+		if r, err := NewReader(data); err != nil || r.NumPage() != 1 {
+			t.Errorf("%s: NewReader err = %v", w, err)
+		}
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	// 任意位置截断的文件都不能越界
+	data := xrefStreamPdf("[1 2 1]")
+	for n := 0; n < len(data); n++ {
+		if r, err := NewReader(data[:n]); err == nil {
+			r.Pages()
+		}
+	}
+	if _, err := NewReader([]byte(strings.Repeat("%PDF-", 3))); err != ErrInvalid {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+// 按顺序写入对象 1..n 的读取器，不经过交叉引用解析
+func objectsReader(objs ...string) *Reader {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.5\n")
+	r := &Reader{xref: map[int]xrefEntry{}, cache: map[int]object{}}
+	for i, o := range objs {
+		r.xref[i+1] = xrefEntry{offset: buf.Len()}
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	r.data = buf.Bytes()
+	return r
+}
+
+// 只含一个对象的对象流，body 为偏移表和对象
+func objStm(first int, body string) string {
+	return fmt.Sprintf("<< /Type /ObjStm /N 1 /First %d /Length %d >>\nstream\n%s\nendstream", first, len(body), body)
+}
+
+func TestObjectStream(t *testing.T) {
+	r := objectsReader(objStm(5, "2 0  << /A 1 >>"))
+	obj, err := r.objectFromStream(1, 0)
+	if d, ok := obj.(dict); err != nil || !ok || d["A"] != int64(1) {
+		t.Fatalf("obj = %v, err = %v", obj, err)
+	}
+
+	// 负的 /First、负的或越界的对象偏移
+	for _, s := range []string{
+		objStm(-100, "2 0  << /A 1 >>"),
+		objStm(5, "2 -100  << /A 1 >>"),
+		objStm(5, "2 9999  << /A 1 >>"),
+		objStm(15, "2 0  << /A 1 >>"),
+	} {
+		r := objectsReader(s)
+		if obj, err := r.objectFromStream(1, 0); err != ErrInvalid {
+			t.Errorf("%q: obj = %v, err = %v", s, obj, err)
+		}
+	}
+}
+
+func TestDeepNesting(t *testing.T) {
+	// 嵌套过深时返回错误，不能耗尽栈空间
+	for _, open := range []string{"[", "<<"} {
+		p := newParser([]byte(strings.Repeat(open, 1000000)), 0, false)
+		if _, err := p.readObject(); err != ErrInvalid {
+			t.Errorf("%s: err = %v", open, err)
+		}
+	}
+	p := newParser([]byte(strings.Repeat("[", 100)+strings.Repeat("]", 100)), 0, false)
+	if _, err := p.readObject(); err != nil {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestFormLimit(t *testing.T) {
+	// 同一个表单被调用多次，每次又调用另一个表单
+	r := objectsReader(
+		"<< /Type /XObject /Subtype /Form /Resources << /XObject << /G 2 0 R >> >> /Length 35 >>\nstream\n/G Do /G Do /G Do /G Do /G Do /G Do\nendstream",
+		"<< /Type /XObject /Subtype /Form /Length 18 >>\nstream\nBT (x) Tj ET\nendstream",
+	)
+	e := &extractor{r: r, fonts: map[objRef]*font{}}
+	res := dict{"XObject": dict{"F": objRef{num: 1}}}
+	e.run([]byte(strings.Repeat("/F Do ", 10000)), res, 0)
+	if e.forms != maxForms {
+		t.Fatalf("forms = %d", e.forms)
+	}
+}
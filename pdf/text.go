@@ -0,0 +1,252 @@
+package pdf
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 每页最多展开的表单 XObject 数
+const maxForms = 1000
+
+// 单页文本层提取结果
+type Page struct {
+	Number   int    `json:"number"`    // 页码，从 1 开始
+	Text     string `json:"text"`      // 文本层内容
+	HasText  bool   `json:"has_text"`  // 是否包含可提取的文本
+	HasImage bool   `json:"has_image"` // 是否包含图片
+}
+
+// 有效字符数，不含空白和控制字符
+func (p Page) TextLen() int {
+	n := 0
+	for _, ch := range p.Text {
+		if !unicode.IsSpace(ch) && unicode.IsPrint(ch) {
+			n++
+		}
+	}
+	return n
+}
+
+// 提取第 num 页（从 1 开始）
+func (r *Reader) Page(num int) (Page, error) {
+	if num < 1 || num > len(r.pages) {
+		return Page{}, errors.New("页码超出范围！")
+	}
+	page := r.pages[num-1]
+	e := &extractor{r: r, fonts: map[objRef]*font{}}
+	resources, _ := r.resolve(page["Resources"]).(dict)
+	e.run(r.pageContent(page), resources, 0)
+
+	res := Page{Number: num, Text: strings.TrimSpace(e.buf.String()), HasImage: e.images > 0}
+	res.HasText = res.TextLen() > 0
+	return res, nil
+}
+
+// 提取全部页面
+func (r *Reader) Pages() ([]Page, error) {
+	pages := make([]Page, 0, len(r.pages))
+	for i := 1; i <= len(r.pages); i++ {
+		p, err := r.Page(i)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, p)
+	}
+	return pages, nil
+}
+
+// 内容流解释器，只关心文本和图片相关操作符
+type extractor struct {
+	r      *Reader
+	buf    strings.Builder
+	fonts  map[objRef]*font
+	font   *font
+	images int
+	forms  int // 已展开的表单 XObject 数
+
+	// 当前行与上次输出文本所在行的纵坐标
+	y            float64
+	lastY        float64
+	pendingBreak bool
+	pendingSpace bool
+}
+
+func (e *extractor) run(content []byte, resources dict, depth int) {
+	if depth > maxDepth/4 {
+		return
+	}
+	p := newParser(content, 0, false)
+	var args []object
+	for !p.eof() {
+		obj, err := p.readObject()
+		if err != nil {
+			return
+		}
+		op, ok := obj.(keyword)
+		if !ok {
+			args = append(args, obj)
+			continue
+		}
+
+		switch op {
+		case "BT":
+			e.y = 0
+		case "Tf":
+			if len(args) >= 2 {
+				if n, ok := args[0].(name); ok {
+					e.font = e.lookupFont(resources, n)
+				}
+			}
+		case "Td", "TD":
+			if len(args) >= 2 {
+				ty, _ := toFloat(args[1])
+				e.y += ty
+			}
+		case "Tm":
+			if len(args) >= 6 {
+				e.y, _ = toFloat(args[5])
+			}
+		case "T*":
+			e.pendingBreak = true
+		case "Tj":
+			if len(args) >= 1 {
+				e.show(args[len(args)-1])
+			}
+		case "'":
+			e.pendingBreak = true
+			if len(args) >= 1 {
+				e.show(args[len(args)-1])
+			}
+		case "\"":
+			e.pendingBreak = true
+			if len(args) >= 3 {
+				e.show(args[2])
+			}
+		case "TJ":
+			if len(args) >= 1 {
+				arr, _ := args[len(args)-1].(array)
+				for _, item := range arr {
+					// 较大的负向字距视为词间空格
+					if v, ok := toFloat(item); ok {
+						if v < -200 {
+							e.pendingSpace = true
+						}
+						continue
+					}
+					e.show(item)
+				}
+			}
+		case "Do":
+			if len(args) >= 1 {
+				if n, ok := args[0].(name); ok {
+					e.xobject(resources, n, depth)
+				}
+			}
+		case "BI":
+			// 内联图片：跳过参数直到 ID，再跳过二进制数据
+			for !p.eof() {
+				obj, err := p.readObject()
+				if err != nil {
+					return
+				}
+				if kw, ok := obj.(keyword); ok && kw == "ID" {
+					break
+				}
+			}
+			p.skipInlineImage()
+			e.images++
+		}
+		args = args[:0]
+	}
+}
+
+func (e *extractor) show(obj object) {
+	s, ok := obj.([]byte)
+	if !ok || e.font == nil {
+		return
+	}
+	text := strings.Map(func(ch rune) rune {
+		if ch == utf8.RuneError || (ch < ' ' && ch != '\t') || unicode.Is(unicode.Co, ch) {
+			return -1
+		}
+		return ch
+	}, e.font.decode(s))
+	if text == "" {
+		return
+	}
+
+	if e.buf.Len() > 0 {
+		last, _ := utf8.DecodeLastRuneInString(e.buf.String())
+		first, _ := utf8.DecodeRuneInString(text)
+		switch {
+		case e.pendingBreak || math.Abs(e.y-e.lastY) > 0.5:
+			if last != '\n' {
+				e.buf.WriteByte('\n')
+			}
+		case e.pendingSpace:
+			// 中日韩文字之间不插入空格
+			if !unicode.IsSpace(last) && !unicode.IsSpace(first) && !isCJK(last) && !isCJK(first) {
+				e.buf.WriteByte(' ')
+			}
+		}
+	}
+	e.pendingBreak = false
+	e.pendingSpace = false
+	e.lastY = e.y
+	e.buf.WriteString(text)
+}
+
+func isCJK(ch rune) bool {
+	return unicode.Is(unicode.Han, ch) || unicode.Is(unicode.Hiragana, ch) ||
+		unicode.Is(unicode.Katakana, ch) || unicode.Is(unicode.Hangul, ch) ||
+		(ch >= 0x3000 && ch <= 0x303F) || (ch >= 0xFF00 && ch <= 0xFFEF)
+}
+
+func (e *extractor) lookupFont(resources dict, n name) *font {
+	fonts, _ := e.r.resolve(resources["Font"]).(dict)
+	ref, isRef := fonts[n].(objRef)
+	if isRef {
+		if f, ok := e.fonts[ref]; ok {
+			return f
+		}
+	}
+	var f *font
+	if d, ok := e.r.resolve(fonts[n]).(dict); ok {
+		f = e.r.loadFont(d)
+	}
+	if isRef {
+		e.fonts[ref] = f
+	}
+	return f
+}
+
+// 处理 XObject：图片计数，表单递归提取
+func (e *extractor) xobject(resources dict, n name, depth int) {
+	xobjects, _ := e.r.resolve(resources["XObject"]).(dict)
+	s, ok := e.r.resolve(xobjects[n]).(*stream)
+	if !ok {
+		return
+	}
+	switch e.r.resolve(s.hdr["Subtype"]) {
+	case name("Image"):
+		e.images++
+	case name("Form"):
+		// 同一表单可被反复调用，按展开总数限制
+		if e.forms >= maxForms {
+			return
+		}
+		e.forms++
+		data, err := e.r.decodeStream(s)
+		if err != nil {
+			return
+		}
+		res, ok := e.r.resolve(s.hdr["Resources"]).(dict)
+		if !ok {
+			res = resources
+		}
+		e.run(data, res, depth+1)
+	}
+}
@@ -0,0 +1,138 @@
+package workspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	dirMu      sync.RWMutex
+	defaultDir string
+)
+
+// 设置默认的临时文件根目录，为空时使用系统临时目录
+func SetDefaultDir(dir string) {
+	dirMu.Lock()
+	defer dirMu.Unlock()
+	defaultDir = dir
+}
+
+// 默认的临时文件根目录
+func DefaultDir() string {
+	dirMu.RLock()
+	defer dirMu.RUnlock()
+	if defaultDir == "" {
+		return os.TempDir()
+	}
+	return defaultDir
+}
+
+// 临时工作区：每个工作区独占一个随机命名的子目录，Cleanup 时整体删除。
+// 工作区只管理自己创建的文件，不会触碰调用方传入的文件。
+type Workspace struct {
+	mu      sync.Mutex
+	dir     string
+	cleaned bool
+}
+
+// 在 root 下创建工作区，root 为空时使用默认目录
+func New(root string) (*Workspace, error) {
+	if root == "" {
+		root = DefaultDir()
+	}
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+	dir, err := os.MkdirTemp(root, "toolkits-")
+	if err != nil {
+		return nil, err
+	}
+	return &Workspace{dir: dir}, nil
+}
+
+// 工作区目录
+func (w *Workspace) Dir() string {
+	return w.dir
+}
+
+// 在工作区内创建唯一命名的文件，suffix 为文件后缀（不含点号）
+func (w *Workspace) CreateFile(suffix string) (*os.File, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cleaned {
+		return nil, errors.New("工作区已清理！")
+	}
+	pattern := "temporary*"
+	if suffix != "" {
+		pattern += "." + cleanSuffix(suffix)
+	}
+	return os.CreateTemp(w.dir, pattern)
+}
+
+// 把 r 的内容写入工作区内的新文件，返回文件路径
+func (w *Workspace) Save(r io.Reader, suffix string) (string, error) {
+	f, err := w.CreateFile(suffix)
+	if err != nil {
+		return "", err
+	}
+	_, err = io.Copy(f, r)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return "", errors.New("写入临时文件时出错！")
+	}
+	return f.Name(), nil
+}
+
+// 下载远程文件到工作区，返回本地路径
+func (w *Workspace) Download(url string, suffix string) (string, error) {
+	return w.DownloadContext(context.Background(), url, suffix)
+}
+
+// 下载远程文件到工作区，ctx 取消时中断下载
+func (w *Workspace) DownloadContext(ctx context.Context, url string, suffix string) (string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return "", fmt.Errorf("下载文件失败，状态码 %d！", response.StatusCode)
+	}
+	return w.Save(response.Body, suffix)
+}
+
+// 删除工作区及其中的全部文件，可重复调用
+func (w *Workspace) Cleanup() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.cleaned {
+		return nil
+	}
+	w.cleaned = true
+	return os.RemoveAll(w.dir)
+}
+
+// 后缀只保留文件名部分，避免 "../" 或查询参数混入路径
+func cleanSuffix(suffix string) string {
+	suffix = filepath.Base(suffix)
+	if i := strings.IndexAny(suffix, "?#"); i >= 0 {
+		suffix = suffix[:i]
+	}
+	suffix = strings.ReplaceAll(suffix, "*", "")
+	return strings.Trim(suffix, ".")
+}